@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Advice is the recommendation of an Advisor about an error.
+type Advice struct {
+	Retry   bool          // whether the operation should be retried
+	Delay   time.Duration // how long to wait before retrying, if Retry
+	Attempt int           // the 1-based number of consecutive failures the advice is based on
+	Reason  string        // why the operation should not be retried, if not Retry
+}
+
+// Advisor answers whether and when to retry an operation for frameworks running their own loop,
+// by consulting a policy the same way Do would: its shouldRetry, its attempt limit and delays,
+// and its FailureRateGate, if any, as well as the marks and delay hints on the errors. It tracks
+// the consecutive failures reported to it, and is safe for concurrent use, although an Advisor is
+// meant for a single operation at a time.
+type Advisor struct {
+	policy Retry
+
+	mu     sync.Mutex
+	streak int
+}
+
+// NewAdvisor creates an Advisor following policy.
+func NewAdvisor(policy Retry) *Advisor {
+	return &Advisor{policy: policy}
+}
+
+// Advise records the outcome err of an attempt and recommends what to do next.
+// A nil err resets the consecutive failures and advises not to retry.
+func (a *Advisor) Advise(err error) Advice {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err == nil {
+		a.streak = 0
+		return Advice{Reason: "succeeded"}
+	}
+	a.streak++
+	return a.policy.Advise(a.streak, err)
+}
+
+// Advise recommends what to do after the attempt-th consecutive failure of an operation, with err,
+// the way an Advisor does, but without tracking the failures. It suits the loops counting them
+// themselves, e.g. persisting the count across restarts.
+func (r Retry) Advise(attempt int, err error) Advice {
+	advice := Advice{Attempt: attempt}
+	switch {
+	case !r.retries(err):
+		advice.Reason = "not retryable"
+	case attempt >= r.maxAttempt && !r.unlimited:
+		advice.Reason = "attempts exhausted"
+	case r.gate != nil && !r.gate.Allow():
+		advice.Reason = ErrRetrySuspended.Error()
+	default:
+		advice.Retry = true
+		advice.Delay = hintedDelay(err)
+		if advice.Delay <= 0 {
+			advice.Delay = r.Delay(attempt)
+		}
+	}
+	return advice
+}
+
+// Reset forgets the consecutive failures, e.g. before starting another operation.
+func (a *Advisor) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.streak = 0
+}
@@ -0,0 +1,30 @@
+package retry
+
+import "context"
+
+// DoAsync is like DoContext, but runs the retry loop in a goroutine and delivers its error, nil on
+// success, on the channel returned, so many operations can be fanned out and their completion selected
+// on. The channel is buffered, so the goroutine ends even if the result is never received.
+func (r Retry) DoAsync(ctx context.Context, f func(context.Context) error) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- r.DoContext(ctx, f)
+	}()
+	return done
+}
+
+// Result is the outcome of DoValueAsync.
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// DoValueAsync is like DoAsync, but for a function returning a value, as DoValue does.
+func DoValueAsync[R any](ctx context.Context, r Retry, f func(ctx context.Context) (R, error)) <-chan Result[R] {
+	done := make(chan Result[R], 1)
+	go func() {
+		v, err := DoValue(ctx, r, f)
+		done <- Result[R]{Value: v, Err: err}
+	}()
+	return done
+}
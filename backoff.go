@@ -0,0 +1,29 @@
+package retry
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff returns the nominal delay before the n-th retry, n starting at 1, before any jitter.
+type Backoff func(n int) time.Duration
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// LinearBackoff waits start before the first retry, and step longer before every further one.
+func LinearBackoff(start time.Duration, step time.Duration) Backoff {
+	return func(n int) time.Duration {
+		if n <= 1 {
+			return start
+		}
+		if step > 0 && time.Duration(n-1) > (math.MaxInt64-start)/step {
+			return math.MaxInt64
+		}
+		return start + time.Duration(n-1)*step
+	}
+}
@@ -0,0 +1,192 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the abort cause when a CircuitBreaker fails an operation fast.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every operation through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every operation fast until the cool-down is over.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe operation through, which closes the breaker if it
+	// succeeds and opens it again if it fails.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker stops calling a failing dependency altogether, where retrying would only add load.
+// Unlike a FailureRateGate, which judges single attempts and only suspends retries, it judges whole
+// operations, i.e. every call of Do with their retries, and fails them fast without any attempt
+// while open.
+//
+// An operation fails if it gives up on a retryable error: it exhausted its attempts, or was aborted
+// for any reason but the cancellation of its context. An operation failing on an error shouldRetry
+// declined is not held against the dependency. The breaker opens after a number of consecutive
+// failed operations, or once their rate exceeds a threshold if WithFailureRate is set, and stays
+// open for a cool-down period. It then lets a single operation probe the dependency.
+//
+// It is safe for concurrent use by many Retry instances.
+type CircuitBreaker struct {
+	failures   int
+	coolDown   time.Duration
+	span       time.Duration // of the window, 0 if the failure rate is not considered
+	threshold  float64
+	minSamples int
+
+	mu          sync.Mutex
+	state       CircuitState
+	consecutive int
+	opened      time.Time
+	probing     bool
+	window      slidingWindow
+}
+
+// NewCircuitBreaker creates a CircuitBreaker opening for coolDown after failures consecutive failed
+// operations. failures <= 0 disables the limit, for a breaker opening on the failure rate only.
+func NewCircuitBreaker(failures int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{failures: failures, coolDown: coolDown}
+}
+
+// WithFailureRate makes a breaker open as well once more than threshold, between 0 and 1, of the
+// operations in the last window failed. The rate is not considered until there are at least minSamples
+// operations in the window. It must be called before a breaker is used.
+func (b *CircuitBreaker) WithFailureRate(window time.Duration, threshold float64, minSamples int) *CircuitBreaker {
+	b.span = window
+	b.threshold = threshold
+	b.minSamples = minSamples
+	b.window = newSlidingWindow(window)
+	return b
+}
+
+// State returns the current state. An open breaker whose cool-down is over reports CircuitHalfOpen,
+// as the next operation would probe.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.opened) >= b.coolDown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Reset closes the breaker and forgets the past operations.
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+}
+
+// allow reports whether an operation may go ahead, taking the probe when half-open.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.opened) < b.coolDown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+	default:
+		return true
+	}
+	b.probing = true
+	return true
+}
+
+// record records the outcome err of an operation let through by allow.
+func (b *CircuitBreaker) record(err error) {
+	failed, counts := operationFailed(err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := b.state == CircuitHalfOpen && b.probing
+	if !counts {
+		if probe {
+			// inconclusive, let another operation probe.
+			b.probing = false
+		}
+		return
+	}
+	if b.span > 0 {
+		b.window.add(failed)
+	}
+	switch {
+	case !failed && probe:
+		b.close()
+	case !failed:
+		b.consecutive = 0
+	case probe:
+		b.open()
+	case b.state == CircuitClosed:
+		b.consecutive++
+		if b.failures > 0 && b.consecutive >= b.failures || b.rateExceeded() {
+			b.open()
+		}
+	}
+}
+
+func (b *CircuitBreaker) rateExceeded() bool {
+	if b.span <= 0 {
+		return false
+	}
+	successes, failures := b.window.count()
+	total := successes + failures
+	return total >= b.minSamples && float64(failures) > b.threshold*float64(total)
+}
+
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.opened = time.Now()
+	b.probing = false
+	b.consecutive = 0
+}
+
+func (b *CircuitBreaker) close() {
+	b.state = CircuitClosed
+	b.probing = false
+	b.consecutive = 0
+	if b.span > 0 {
+		b.window = newSlidingWindow(b.span)
+	}
+}
+
+// operationFailed tells whether the outcome err of an operation counts against the dependency,
+// and whether it counts at all.
+func operationFailed(err error) (failed bool, counts bool) {
+	switch e := err.(type) {
+	case nil:
+		return false, true
+	case *ErrMaxAttemptExceeded:
+		return true, true
+	case *ErrAborted:
+		if e.Err == nil || errors.Is(e.Cause, context.Canceled) {
+			return false, false
+		}
+		return true, true
+	}
+	return false, false
+}
@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExhausted is the abort cause when a RetryBudget has no token left for a retry.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget caps the retries across every policy sharing it to a fraction of the calls, so the
+// load sent to a struggling dependency grows by at most that fraction instead of multiplying by the
+// attempt limit. It is a token bucket: every first attempt deposits ratio tokens, up to burst, and
+// every retry withdraws one. A retry finding less than one token is skipped, and Do gives up with
+// ErrAborted matching ErrBudgetExhausted. It is safe for concurrent use by many Retry instances.
+type RetryBudget struct {
+	ratio float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// NewRetryBudget creates a RetryBudget allowing ratio retries per call, e.g. 0.2 for no more than
+// 20% extra load, and holding at most burst tokens, which it starts with.
+func NewRetryBudget(ratio float64, burst float64) *RetryBudget {
+	return &RetryBudget{ratio: ratio, burst: burst, tokens: burst}
+}
+
+// Tokens returns the number of tokens left, i.e. how many retries may be made right now.
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// deposit records a call.
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// withdraw takes a token for a retry, if there is one.
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
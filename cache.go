@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// minSweep is the number of entries below which FailureCache does not bother sweeping expired ones.
+const minSweep = 1024
+
+// FailureCache remembers recent permanent failures, i.e. errors shouldRetry declined to retry,
+// by key, so later calls for the same key fail fast instead of discovering the failure again.
+// It is safe for concurrent use.
+type FailureCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]failureEntry
+	sweepAt int
+}
+
+type failureEntry struct {
+	err     error
+	expires time.Time
+}
+
+// NewFailureCache creates a FailureCache remembering every failure for ttl.
+func NewFailureCache(ttl time.Duration) *FailureCache {
+	return &FailureCache{
+		ttl:     ttl,
+		entries: map[string]failureEntry{},
+		sweepAt: minSweep,
+	}
+}
+
+// Get returns the permanent failure remembered for key, or nil if there is none.
+func (c *FailureCache) Get(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	return e.err
+}
+
+// Forget drops the failure remembered for key, e.g. after the misconfiguration is fixed.
+func (c *FailureCache) Forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// DoContext returns the failure remembered for key without calling f if there is one.
+// Otherwise it calls r.DoContext and remembers the error if it is permanent. Failures of the
+// caller rather than of the operation, i.e. ctx being done or a context error, are not remembered.
+func (c *FailureCache) DoContext(ctx context.Context, r Retry, key string, f func(context.Context) error) error {
+	if err := c.Get(key); err != nil {
+		return err
+	}
+	err := r.DoContext(ctx, f)
+	if err != nil && ctx.Err() == nil && isPermanent(err) {
+		c.put(key, err)
+	}
+	return err
+}
+
+func (c *FailureCache) put(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= c.sweepAt {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweepAt = 2 * len(c.entries)
+		if c.sweepAt < minSweep {
+			c.sweepAt = minSweep
+		}
+	}
+	c.entries[key] = failureEntry{err: err, expires: now.Add(c.ttl)}
+}
+
+// isPermanent reports whether err returned by Do is an error shouldRetry declined to retry,
+// rather than the retry giving up or a context error, which says nothing about the key.
+func isPermanent(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var exceeded *ErrMaxAttemptExceeded
+	var aborted *ErrAborted
+	return !errors.As(err, &exceeded) && !errors.As(err, &aborted)
+}
@@ -0,0 +1,43 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// IsTemporaryNetErr is a shouldRetry predicate retrying the transient failures of the network:
+// timeouts, connections reset, refused or aborted, connections cut short, and temporary failures
+// of DNS. Errors of the context are not retried, although context.DeadlineExceeded is a timeout,
+// since the caller gave up rather than the network.
+func IsTemporaryNetErr(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return IsTemporaryDNSErr(dnsErr)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsTemporaryDNSErr is a shouldRetry predicate retrying the lookups failed with a *net.DNSError
+// which is temporary or timed out. A host not found is not retried, even if the resolver flagged
+// the failure as temporary too, since the answer is authoritative. Other errors are not retried.
+func IsTemporaryDNSErr(err error) bool {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || dnsErr.IsNotFound {
+		return false
+	}
+	return dnsErr.IsTemporary || dnsErr.IsTimeout
+}
@@ -0,0 +1,36 @@
+package retry
+
+import "time"
+
+// Clock is the source of time of a Retry, so tests can fake it and run a long schedule instantly.
+// Sleep is offered for the convenience of the functions retried; Do waits with NewTimer,
+// so it can stop waiting once the context is done.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer made by a Clock, like time.Timer.
+type Timer interface {
+	// C returns the channel receiving the time when the timer fires.
+	C() <-chan time.Time
+	// Stop stops the timer, and reports whether it was still pending.
+	Stop() bool
+}
+
+// SystemClock is the Clock of the time package, used by default.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                 { return time.Now() }
+func (systemClock) Sleep(d time.Duration)          { time.Sleep(d) }
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+func (t systemTimer) Stop() bool          { return t.t.Stop() }
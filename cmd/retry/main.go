@@ -0,0 +1,156 @@
+// Command retry runs a shell command and retries it with backoff until it succeeds.
+//
+//	retry [flags] -- command [args...]
+//
+// The policy is set by the flags, or loaded from a JSON file in the format of retry.Policy with -policy,
+// which replaces the policy flags.
+//
+// The exit code of retry is the exit code of the last run of the command, 1 if the command could
+// not be run at all, or 2 if the flags are invalid.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs retry with the command line arguments args, and returns its exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("retry", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var p retry.Policy
+	flags.IntVar(&p.MaxAttempts, "attempts", 5, "max attempts")
+	flags.DurationVar(&p.InitialDelay, "init-delay", 100*time.Millisecond, "delay before the first retry")
+	flags.DurationVar(&p.MaxDelay, "max-delay", 10*time.Second, "max delay between retries")
+	flags.StringVar(&p.Backoff, "backoff", retry.BackoffExponential, "backoff type: exponential, constant or linear")
+	flags.DurationVar(&p.Step, "step", 0, "increase of the delay on every retry with -backoff linear")
+	flags.TextVar(&p.Jitter, "jitter", retry.FullJitter, "randomization of the delays: full, equal, decorrelated or none")
+	noJitter := flags.Bool("no-jitter", false, "same as -jitter none")
+	flags.DurationVar(&p.MaxElapsedTime, "max-elapsed", 0, "give up after this long in total, 0 for no limit")
+	flags.DurationVar(&p.AttemptTimeout, "attempt-timeout", 0, "kill a run of the command after this long, 0 for no limit")
+	policyFile := flags.String("policy", "", "JSON file of the policy, replacing the policy flags")
+	retryOn := flags.String("retry-on", "", "comma separated exit codes to retry on, empty for any non-zero exit code")
+	quiet := flags.Bool("quiet", false, "do not report failed attempts to stderr")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: retry [flags] -- command [args...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	codes, err := parseCodes(*retryOn)
+	if err != nil {
+		fmt.Fprintf(stderr, "retry: invalid -retry-on: %v\n", err)
+		return 2
+	}
+	if *noJitter {
+		p.Jitter = retry.NoJitter
+	}
+	if *policyFile != "" {
+		if p, err = loadPolicy(*policyFile); err != nil {
+			fmt.Fprintf(stderr, "retry: invalid -policy: %v\n", err)
+			return 2
+		}
+	}
+	r, err := retry.FromConfig(p, shouldRetry(codes))
+	if err != nil {
+		fmt.Fprintf(stderr, "retry: %v\n", err)
+		return 2
+	}
+
+	command := flags.Args()
+	attempt := 0
+	err = r.DoContext(ctx, func(ctx context.Context) error {
+		attempt++
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		err := cmd.Run()
+		if err != nil && !*quiet {
+			fmt.Fprintf(stderr, "retry: attempt %d: %v\n", attempt, err)
+		}
+		return err
+	})
+	return exitCode(err, stderr)
+}
+
+// loadPolicy reads the policy in the JSON file at path.
+func loadPolicy(path string) (retry.Policy, error) {
+	var p retry.Policy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(data, &p)
+	return p, err
+}
+
+// parseCodes parses a comma separated list of exit codes.
+func parseCodes(s string) (map[int]bool, error) {
+	codes := map[int]bool{}
+	if s == "" {
+		return codes, nil
+	}
+	for _, field := range strings.Split(s, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		codes[code] = true
+	}
+	return codes, nil
+}
+
+// shouldRetry retries on the exit codes in codes, or on any exit code if codes is empty.
+// Failing to start the command is never retried.
+func shouldRetry(codes map[int]bool) func(error) bool {
+	return func(err error) bool {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return false
+		}
+		return len(codes) == 0 || codes[exitErr.ExitCode()]
+	}
+}
+
+// exitCode returns the exit code of retry for the outcome err of the retries, reporting to stderr
+// why it gave up if it did before the attempts ran out.
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+	var aborted *retry.ErrAborted
+	if errors.As(err, &aborted) {
+		fmt.Fprintf(stderr, "retry: gave up: %v\n", aborted.Cause)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
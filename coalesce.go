@@ -0,0 +1,71 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// Coalescer merges retries pending for the same key into one execution and hands its result
+// to every caller waiting on the key, so duplicate work items do not retry the same thing many
+// times over. It is safe for concurrent use.
+type Coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done    chan struct{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewCoalescer creates a Coalescer.
+func NewCoalescer() *Coalescer {
+	return &Coalescer{calls: map[string]*coalescedCall{}}
+}
+
+// DoContext calls r.DoContext for key unless a call for key is already in flight, in which case
+// it waits for that call and returns its error instead. The execution keeps the values of the
+// context of the caller starting it, but it is canceled only when every caller waiting on it
+// has given up. A caller whose ctx is done before the result is in returns ErrAborted.
+func (c *Coalescer) DoContext(ctx context.Context, r Retry, key string, f func(context.Context) error) error {
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = call
+		go c.run(runCtx, r, key, call, f)
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// nobody is left for the result, and a later caller should not inherit the cancellation.
+			call.cancel()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+		return &ErrAborted{Cause: ctx.Err()}
+	}
+}
+
+func (c *Coalescer) run(ctx context.Context, r Retry, key string, call *coalescedCall, f func(context.Context) error) {
+	call.err = r.DoContext(ctx, f)
+	call.cancel()
+	c.mu.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
@@ -0,0 +1,39 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Default returns a general purpose policy: 5 attempts, the delays doubling from 100ms up to 5s
+// with FullJitter, for at most 1.5s of sleep in total and about 0.75s on average. It retries every
+// error but those of the context, which mean the caller gave up, and those marked Unrecoverable.
+func Default() Retry {
+	return NewWithDurations(isNotContextErr, 5, 100*time.Millisecond, 5*time.Second)
+}
+
+// Network returns a policy for calls over the network: 6 attempts, the delays doubling from 200ms
+// up to 10s with FullJitter. It retries the transient failures of the network, see IsTemporaryNetErr.
+func Network() Retry {
+	return NewWithDurations(IsTemporaryNetErr, 6, 200*time.Millisecond, 10*time.Second)
+}
+
+// AggressiveShort returns a policy for fast local operations contending for a resource, such as
+// a lock or an optimistic transaction: 10 attempts, the delays doubling from 5ms up to 100ms with
+// EqualJitter, giving up within about a second. It retries the errors Default retries.
+func AggressiveShort() Retry {
+	return NewWithDurations(isNotContextErr, 10, 5*time.Millisecond, 100*time.Millisecond).WithJitter(EqualJitter)
+}
+
+// SlowPoll returns a policy polling for a state which takes a while to be reached, such as a job
+// completing: 60 attempts, the delays doubling from 1s up to 30s with EqualJitter, i.e. about
+// 20 minutes in all. It retries the errors Default retries, typically a sentinel error returned
+// until the state is reached.
+func SlowPoll() Retry {
+	return NewWithDurations(isNotContextErr, 60, time.Second, 30*time.Second).WithJitter(EqualJitter)
+}
+
+func isNotContextErr(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,8 @@
+// Package retry retries a function under the specific conditions, sleeping an exponentially
+// growing and randomized delay between the attempts.
+//
+// All the elapsed time accounting, such as delays, attempt timeouts and deadlines, is done with
+// the monotonic clock readings carried by time.Time, so it is immune to wall clock jumps caused by
+// NTP steps or suspend and resume. Timestamps reported to the caller, such as AttemptError.Time,
+// still carry the wall clock for display.
+package retry
@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Each calls f for every item of items, and retries only the items which failed in rounds, the whole
+// round counting as an attempt of policy: the delay of the policy, or the longest one hinted by the
+// errors, separates the rounds, and the items still failing after the max attempts of the policy are
+// given up on. It is the pattern of bulk writes where a subset of the items is throttled. Up to
+// concurrency items are in flight at once in a round, or all of them if concurrency <= 0.
+//
+// Each returns nil if every item eventually succeeded, otherwise the errors by index of items, nil
+// for the items which succeeded: the error itself if the policy does not retry it,
+// ErrMaxAttemptExceeded if the item failed in every round, or ErrAborted if ctx is done first.
+// Every item fails with ErrInvalidConfig, without any call of f, if the policy is invalid.
+func Each[T any](ctx context.Context, policy Retry, items []T, concurrency int, f func(ctx context.Context, item T) error) []error {
+	r := policy
+	errs := make([]error, len(items))
+	if err := r.Validate(); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	pending := make([]int, len(items))
+	for i := range pending {
+		pending[i] = i
+	}
+	failed := false
+	for round := 1; len(pending) > 0; round++ {
+		if err := ctx.Err(); err != nil {
+			for _, i := range pending {
+				errs[i] = &ErrAborted{Cause: err, Err: errs[i]}
+			}
+			return errs
+		}
+		eachRound(ctx, items, pending, concurrency, f, errs)
+		retried := pending[:0]
+		var wait time.Duration
+		for _, i := range pending {
+			err := errs[i]
+			switch {
+			case err == nil:
+			case !r.retries(err):
+				errs[i] = unmark(err)
+				failed = true
+			case round == r.maxAttempt && !r.unlimited:
+				errs[i] = &ErrMaxAttemptExceeded{Err: err, AttemptsMade: round}
+				failed = true
+			default:
+				retried = append(retried, i)
+				if d := hintedDelay(err); d > wait {
+					wait = d
+				}
+			}
+		}
+		pending = retried
+		if len(pending) == 0 {
+			break
+		}
+		if wait <= 0 {
+			wait = r.Delay(round)
+		}
+		if err := r.sleep(ctx, wait); err != nil {
+			for _, i := range pending {
+				errs[i] = &ErrAborted{Cause: err, Err: errs[i]}
+			}
+			return errs
+		}
+	}
+	if !failed {
+		return nil
+	}
+	return errs
+}
+
+// eachRound calls f for the items at the indexes pending, up to concurrency at once, and stores their errors in errs.
+func eachRound[T any](ctx context.Context, items []T, pending []int, concurrency int, f func(ctx context.Context, item T) error, errs []error) {
+	if concurrency <= 0 || concurrency > len(pending) {
+		concurrency = len(pending)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = f(ctx, items[i])
+			}
+		}()
+	}
+	for _, i := range pending {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,157 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	// ErrCanceled matches, via errors.Is, the error returned when the retry loop is aborted
+	// because its context was canceled.
+	ErrCanceled = errors.New("retry canceled")
+	// ErrDeadlineExceeded matches, via errors.Is, the error returned when the retry loop is aborted
+	// because its deadline passed.
+	ErrDeadlineExceeded = errors.New("retry deadline exceeded")
+	// ErrExhausted matches, via errors.Is, the error returned when all attempts failed.
+	ErrExhausted = errors.New("retry attempts exhausted")
+	// ErrDependencyDown is the abort cause when the health check of Retry.WithHealthCheck fails.
+	ErrDependencyDown = errors.New("dependency is down")
+)
+
+// errSleepExhausted is the abort cause when the delays used up the limit of WithMaxTotalSleep.
+var errSleepExhausted = fmt.Errorf("max total sleep exceeded: %w", context.DeadlineExceeded)
+
+// errElapsedExhausted is the abort cause when the attempts and delays used up the limit of
+// WithMaxElapsedTime, tracked by the loop because of WithExcludeMandatedWaits.
+var errElapsedExhausted = fmt.Errorf("max elapsed time exceeded: %w", context.DeadlineExceeded)
+
+// ErrMaxAttemptExceeded wraps the original error when the max retry attempt exceeded.
+// Err is the error of the last attempt and Attempts records the error of every attempt.
+// Attempts is bounded by Retry.WithHistoryLimit and Dropped counts the attempt errors left out.
+// AttemptsMade is the number of attempts made, Elapsed how long the retry loop ran,
+// and Slept how much of it was spent sleeping between attempts.
+type ErrMaxAttemptExceeded struct {
+	Err          error
+	Attempts     []AttemptError
+	Dropped      int
+	AttemptsMade int
+	Elapsed      time.Duration
+	Slept        time.Duration
+}
+
+func (e *ErrMaxAttemptExceeded) Error() string {
+	return fmt.Sprintf("exceed max retry attempts. Original error: %v", e.Err.Error())
+}
+
+// Errors returns the errors of the attempts kept in Attempts, in order, the last one being Err.
+func (e *ErrMaxAttemptExceeded) Errors() []error {
+	if len(e.Attempts) == 0 {
+		return []error{e.Err}
+	}
+	errs := make([]error, len(e.Attempts))
+	for i := range e.Attempts {
+		errs[i] = e.Attempts[i].Err
+	}
+	return errs
+}
+
+// Unwrap returns the errors of every attempt kept, like an error made by errors.Join,
+// so errors.Is and errors.As match any of them.
+func (e *ErrMaxAttemptExceeded) Unwrap() []error {
+	return e.Errors()
+}
+
+// Format prints the error like Error, but with the %+v verb it lists every attempt kept as well.
+func (e *ErrMaxAttemptExceeded) Format(s fmt.State, verb rune) {
+	io.WriteString(s, e.Error())
+	if verb != 'v' || !s.Flag('+') {
+		return
+	}
+	for i := range e.Attempts {
+		if i == e.droppedAt() {
+			fmt.Fprintf(s, "\n\t... %d attempts dropped", e.Dropped)
+		}
+		fmt.Fprintf(s, "\n\t%v", &e.Attempts[i])
+	}
+}
+
+// droppedAt returns the index in Attempts where attempts were dropped, or -1 if none were.
+func (e *ErrMaxAttemptExceeded) droppedAt() int {
+	if e.Dropped == 0 {
+		return -1
+	}
+	return len(e.Attempts) / 2
+}
+
+// Is reports whether target is ErrExhausted, so errors.Is works no matter how deep the error is wrapped.
+func (e *ErrMaxAttemptExceeded) Is(target error) bool {
+	return target == ErrExhausted
+}
+
+// ErrInvalidConfig returns when a Retry is constructed with an invalid parameter.
+type ErrInvalidConfig struct {
+	Param  string
+	Reason string
+}
+
+func (e *ErrInvalidConfig) Error() string {
+	return fmt.Sprintf("invalid retry config: %s %s", e.Param, e.Reason)
+}
+
+// ErrAborted returns when the retry loop stops before the attempts are used up.
+// Cause is the reason of the abort, e.g. context.Canceled or context.DeadlineExceeded.
+// Err is the error of the last attempt, or nil if no attempt was made.
+// Attempts records the error of every attempt made before the abort, bounded like in ErrMaxAttemptExceeded.
+type ErrAborted struct {
+	Cause    error
+	Err      error
+	Attempts []AttemptError
+	Dropped  int
+}
+
+func (e *ErrAborted) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("retry aborted: %v", e.Cause)
+	}
+	return fmt.Sprintf("retry aborted: %v. Original error: %v", e.Cause, e.Err)
+}
+
+func (e *ErrAborted) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Cause}
+	}
+	return []error{e.Cause, e.Err}
+}
+
+// Is reports whether target is ErrCanceled or ErrDeadlineExceeded matching the abort cause.
+func (e *ErrAborted) Is(target error) bool {
+	switch target {
+	case ErrCanceled:
+		return errors.Is(e.Cause, context.Canceled)
+	case ErrDeadlineExceeded:
+		return errors.Is(e.Cause, context.DeadlineExceeded)
+	}
+	return false
+}
+
+// AttemptError records the error returned by a single attempt.
+// Attempt is the 1-based attempt number and Time is when the attempt returned the error.
+// Duration is how long the attempt took, and Delay how long Do slept after it before the next one.
+type AttemptError struct {
+	Attempt  int
+	Time     time.Time
+	Err      error
+	Duration time.Duration
+	Delay    time.Duration
+}
+
+func (e *AttemptError) Error() string {
+	return fmt.Sprintf("attempt %d: %v", e.Attempt, e.Err)
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,19 @@
+package retry
+
+import "expvar"
+
+// PublishExpvar publishes the live statistics of r under name in expvar, so they show in /debug/vars
+// along with the average attempts per call. r must count them, see WithStats. Like expvar.Publish,
+// it panics if name is already in use.
+func PublishExpvar(name string, r Retry) {
+	if r.stats == nil {
+		panic("r must count statistics, see WithStats")
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		s := r.stats.snapshot()
+		return struct {
+			Stats
+			AvgAttempts float64
+		}{s, s.AvgAttempts()}
+	}))
+}
@@ -0,0 +1,163 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// FailoverMode decides when Failover moves on to the next endpoint.
+type FailoverMode int
+
+const (
+	// AdvanceOnExhaustion retries an endpoint with the policy until it gives up,
+	// then moves on to the next endpoint with a fresh schedule.
+	AdvanceOnExhaustion FailoverMode = iota
+	// AdvancePerAttempt moves on to the next endpoint after every failed attempt, round robin.
+	// Each endpoint keeps its own schedule: the first attempt at every endpoint is made without delay,
+	// and an endpoint is retried after the delay for its own number of failures.
+	// Every endpoint is attempted up to the max attempts of the policy.
+	AdvancePerAttempt
+)
+
+// Failover retries an operation across an ordered or weighted list of endpoints, e.g. a primary,
+// its secondaries and other regions.
+type Failover[E any] struct {
+	policy    Retry
+	mode      FailoverMode
+	endpoints []E
+	weights   []int // nil for the fixed order
+}
+
+// NewFailover creates a Failover trying endpoints in order with policy.
+func NewFailover[E any](policy Retry, mode FailoverMode, endpoints ...E) *Failover[E] {
+	if len(endpoints) == 0 {
+		panic("endpoints must not be empty")
+	}
+	return &Failover[E]{
+		policy:    policy,
+		mode:      mode,
+		endpoints: endpoints,
+	}
+}
+
+// NewWeightedFailover creates a Failover trying endpoints in a random order drawn for every call,
+// where an endpoint is more likely to come earlier the larger its weight is.
+// weights must have the same length as endpoints and be positive.
+func NewWeightedFailover[E any](policy Retry, mode FailoverMode, endpoints []E, weights []int) *Failover[E] {
+	if len(weights) != len(endpoints) {
+		panic("weights must have the same length as endpoints")
+	}
+	for _, w := range weights {
+		if w <= 0 {
+			panic("weights must be greater than 0")
+		}
+	}
+	fo := NewFailover(policy, mode, endpoints...)
+	fo.weights = weights
+	return fo
+}
+
+// order returns the endpoints in the order to try them for a call.
+func (fo *Failover[E]) order() []E {
+	if fo.weights == nil {
+		return fo.endpoints
+	}
+	endpoints := append([]E(nil), fo.endpoints...)
+	weights := append([]int(nil), fo.weights...)
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	for i := range endpoints {
+		// pick the i-th endpoint among the remaining ones, weighted.
+		pick := int(fo.policy.rand.Float64() * float64(total))
+		j := i
+		for ; j < len(endpoints)-1 && pick >= weights[j]; j++ {
+			pick -= weights[j]
+		}
+		endpoints[i], endpoints[j] = endpoints[j], endpoints[i]
+		weights[i], weights[j] = weights[j], weights[i]
+		total -= weights[i]
+	}
+	return endpoints
+}
+
+// DoContext calls f with the endpoints until it succeeds, returns an error the policy does not retry,
+// or every endpoint is used up. In the last case, the error of the last endpoint returns.
+//
+// With AdvanceOnExhaustion, every endpoint is retried with the whole policy. With AdvancePerAttempt,
+// only the predicate, the attempt limit, the delays, the clock and the history limit of the policy
+// apply, as well as the marks on the errors; its hooks, observer, logger, limiter and time limits do not.
+func (fo *Failover[E]) DoContext(ctx context.Context, f func(ctx context.Context, endpoint E) error) error {
+	endpoints := fo.order()
+	if fo.mode == AdvancePerAttempt {
+		return fo.roundRobin(ctx, endpoints, f)
+	}
+	var err error
+	for _, endpoint := range endpoints {
+		endpoint := endpoint
+		err = fo.policy.DoContext(ctx, func(ctx context.Context) error {
+			return f(ctx, endpoint)
+		})
+		if !isExhausted(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (fo *Failover[E]) roundRobin(ctx context.Context, endpoints []E, f func(ctx context.Context, endpoint E) error) error {
+	r := fo.policy
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	n := len(endpoints)
+	total := r.maxAttempt * n
+	if r.unlimited || (n > 0 && r.maxAttempt > math.MaxInt/n) {
+		total = math.MaxInt
+	}
+	failures := make([]int, n)
+	hist := history{limit: r.historyLimit}
+	begin := r.now()
+	var slept time.Duration
+	var lastErr error
+	for attempt := 1; attempt <= total; attempt++ {
+		i := (attempt - 1) % n
+		if failures[i] > 0 {
+			wait := r.Delay(failures[i])
+			if err := r.sleep(ctx, wait); err != nil {
+				return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.dropped}
+			}
+			slept += wait
+		}
+		if err := ctx.Err(); err != nil {
+			return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.dropped}
+		}
+		start := r.now()
+		lastErr = f(ctx, endpoints[i])
+		if lastErr == nil {
+			return nil
+		}
+		if !r.retries(lastErr) {
+			return unmark(lastErr)
+		}
+		failures[i]++
+		now := r.now()
+		hist.add(AttemptError{Attempt: attempt, Time: now, Err: lastErr, Duration: now.Sub(start)})
+	}
+	return &ErrMaxAttemptExceeded{
+		Err:          lastErr,
+		Attempts:     hist.attempts(),
+		Dropped:      hist.dropped,
+		AttemptsMade: total,
+		Elapsed:      r.now().Sub(begin),
+		Slept:        slept,
+	}
+}
+
+// isExhausted reports whether err is ErrMaxAttemptExceeded, i.e. the policy gave up on an endpoint.
+func isExhausted(err error) bool {
+	_, ok := err.(*ErrMaxAttemptExceeded)
+	return ok
+}
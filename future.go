@@ -0,0 +1,43 @@
+package retry
+
+import "context"
+
+// Future is the pending outcome of an operation started by Go.
+type Future[R any] struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	value  R
+	err    error
+}
+
+// Go starts retrying f with policy in a goroutine, as DoValue does, and returns its Future, so
+// a long-running operation can be started early and joined later. The context of the attempts is
+// derived from ctx, and canceled by Future.Cancel.
+func Go[R any](ctx context.Context, policy Retry, f func(ctx context.Context) (R, error)) *Future[R] {
+	ctx, cancel := context.WithCancel(ctx)
+	fut := &Future[R]{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer cancel()
+		fut.value, fut.err = DoValue(ctx, policy, f)
+		close(fut.done)
+	}()
+	return fut
+}
+
+// Wait waits for the operation to end and returns its value and error, like DoValue.
+func (f *Future[R]) Wait() (R, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// Done returns a channel closed once the operation ended, after which Wait returns at once.
+func (f *Future[R]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel cancels the context of the operation, interrupting the attempt in flight or the delay
+// before the next one. The operation then ends with ErrAborted, unless it completed already.
+// It does not wait for the operation to end.
+func (f *Future[R]) Cancel() {
+	f.cancel()
+}
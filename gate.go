@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRetrySuspended is the abort cause when a FailureRateGate suspended retries.
+var ErrRetrySuspended = errors.New("retries suspended by failure rate")
+
+// FailureRateGate tracks the failure rate of attempts in a sliding window, and suspends retries
+// for a cool-down period once the rate exceeds a threshold, so every call makes a single attempt
+// during a failure storm instead of amplifying it. It is a lighter alternative to a circuit breaker,
+// and is safe for concurrent use by many Retry instances.
+type FailureRateGate struct {
+	threshold  float64
+	minSamples int
+	coolDown   time.Duration
+
+	mu        sync.Mutex
+	window    slidingWindow
+	suspended time.Duration // since the origin of window, until when retries are suspended
+}
+
+// NewFailureRateGate creates a FailureRateGate suspending retries for coolDown once more than
+// threshold, between 0 and 1, of the attempts in the last window failed.
+// The rate is not considered until there are at least minSamples attempts in the window.
+func NewFailureRateGate(window time.Duration, threshold float64, minSamples int, coolDown time.Duration) *FailureRateGate {
+	return &FailureRateGate{
+		threshold:  threshold,
+		minSamples: minSamples,
+		coolDown:   coolDown,
+		window:     newSlidingWindow(window),
+	}
+}
+
+// Record records the outcome of an attempt.
+func (g *FailureRateGate) Record(failed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.window.add(failed)
+	if !failed {
+		return
+	}
+	successes, failures := g.window.count()
+	total := successes + failures
+	if total >= g.minSamples && float64(failures) > g.threshold*float64(total) {
+		g.suspended = g.window.now() + g.coolDown
+	}
+}
+
+// Allow reports whether retries are allowed now.
+func (g *FailureRateGate) Allow() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.window.now() >= g.suspended
+}
+
+// Rate returns the failure rate in the current window, and the number of attempts it is based on.
+func (g *FailureRateGate) Rate() (float64, int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	successes, failures := g.window.count()
+	total := successes + failures
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(total), total
+}
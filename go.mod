@@ -1,3 +1,3 @@
 module github.com/bluexlab/retry-go
 
-go 1.20
+go 1.21
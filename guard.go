@@ -0,0 +1,159 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRetryRejected is the abort cause when a Guard rejects a retry.
+var ErrRetryRejected = errors.New("retry rejected by guard")
+
+var globalGuard atomic.Pointer[Guard]
+
+// SetGlobalGuard installs g as the process-wide guard consulted by every Retry before retrying.
+// Passing nil removes it.
+func SetGlobalGuard(g *Guard) {
+	globalGuard.Store(g)
+}
+
+// GlobalGuard returns the process-wide guard, or nil if there is none.
+func GlobalGuard() *Guard {
+	return globalGuard.Load()
+}
+
+// Guard caps the retrying across every policy sharing it: at most maxConcurrent operations may
+// be retrying at once, and at most maxPerSecond retries may start per second. During a broad
+// outage, the sum of individually reasonable policies could otherwise still melt the egress.
+// A retry over the caps either waits for its turn or is rejected, in which case Do gives up
+// with ErrAborted matching ErrRetryRejected. The first attempt of an operation is never held back.
+type Guard struct {
+	slots        chan struct{} // nil for no concurrency cap
+	maxPerSecond float64       // 0 for no rate cap
+	queue        bool
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	admitted atomic.Uint64
+	rejected atomic.Uint64
+	queued   atomic.Uint64
+}
+
+// GuardStats is a snapshot of the counters of a Guard.
+type GuardStats struct {
+	Active   int    // operations retrying at the moment
+	Admitted uint64 // retries let through
+	Rejected uint64 // retries rejected
+	Queued   uint64 // retries which had to wait for their turn
+}
+
+// NewGuard creates a Guard. maxConcurrent <= 0 or maxPerSecond <= 0 disables the respective cap.
+// With queue a retry over the caps waits for its turn, otherwise it is rejected.
+func NewGuard(maxConcurrent int, maxPerSecond float64, queue bool) *Guard {
+	g := &Guard{
+		maxPerSecond: maxPerSecond,
+		queue:        queue,
+		tokens:       maxPerSecond,
+		last:         time.Now(),
+	}
+	if maxConcurrent > 0 {
+		g.slots = make(chan struct{}, maxConcurrent)
+	}
+	return g
+}
+
+// Stats returns a snapshot of the counters.
+func (g *Guard) Stats() GuardStats {
+	return GuardStats{
+		Active:   len(g.slots),
+		Admitted: g.admitted.Load(),
+		Rejected: g.rejected.Load(),
+		Queued:   g.queued.Load(),
+	}
+}
+
+// enter takes a slot for an operation starting to retry.
+func (g *Guard) enter(ctx context.Context) error {
+	if g.slots == nil {
+		return nil
+	}
+	select {
+	case g.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if !g.queue {
+		g.rejected.Add(1)
+		return ErrRetryRejected
+	}
+	g.queued.Add(1)
+	select {
+	case g.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// leave releases the slot taken by enter.
+func (g *Guard) leave() {
+	if g.slots != nil {
+		<-g.slots
+	}
+}
+
+// admit takes a token for a retry.
+func (g *Guard) admit(ctx context.Context) error {
+	if g.maxPerSecond <= 0 {
+		g.admitted.Add(1)
+		return nil
+	}
+	queued := false
+	for {
+		wait := g.take()
+		if wait == 0 {
+			g.admitted.Add(1)
+			return nil
+		}
+		if !g.queue {
+			g.rejected.Add(1)
+			return ErrRetryRejected
+		}
+		if !queued {
+			queued = true
+			g.queued.Add(1)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// take takes a token if there is one, otherwise returns how long until there is.
+func (g *Guard) take() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	burst := g.maxPerSecond
+	if burst < 1 {
+		burst = 1
+	}
+	g.tokens += now.Sub(g.last).Seconds() * g.maxPerSecond
+	if g.tokens > burst {
+		g.tokens = burst
+	}
+	g.last = now
+	if g.tokens >= 1 {
+		g.tokens--
+		return 0
+	}
+	return time.Duration((1 - g.tokens) / g.maxPerSecond * float64(time.Second))
+}
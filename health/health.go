@@ -0,0 +1,145 @@
+// Package health probes the health of a dependency with the backoff of retry.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// Status is the health status of a dependency.
+type Status int
+
+const (
+	// Unknown is the status before the first probe completes.
+	Unknown Status = iota
+	// Healthy means the last probe succeeded.
+	Healthy
+	// Unhealthy means the probe keeps failing.
+	Unhealthy
+)
+
+func (s Status) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case Unhealthy:
+		return "unhealthy"
+	}
+	return "unknown"
+}
+
+// Transition describes a change of the health status.
+// Err is the probe error which made the dependency unhealthy, or nil.
+type Transition struct {
+	From Status
+	To   Status
+	Err  error
+	Time time.Time
+}
+
+// Prober probes a dependency periodically. While healthy it probes every interval, confirming
+// a failure with the quick retries of its policy before declaring the dependency unhealthy.
+// While unhealthy it probes with the growing delays of the policy until a probe succeeds.
+type Prober struct {
+	probe    func(context.Context) error
+	policy   retry.Retry
+	interval time.Duration
+	onChange func(Transition)
+
+	mu      sync.Mutex
+	status  Status
+	lastErr error
+}
+
+// NewProber creates a Prober. onChange is called on every status transition and may be nil.
+func NewProber(probe func(context.Context) error, policy retry.Retry, interval time.Duration, onChange func(Transition)) *Prober {
+	return &Prober{
+		probe:    probe,
+		policy:   policy,
+		interval: interval,
+		onChange: onChange,
+	}
+}
+
+// Status returns the current status and the last probe error, if any.
+func (p *Prober) Status() (Status, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status, p.lastErr
+}
+
+// Check reports whether the dependency is not known to be unhealthy.
+// It fits retry.Retry.WithHealthCheck.
+func (p *Prober) Check(context.Context) bool {
+	status, _ := p.Status()
+	return status != Unhealthy
+}
+
+// Run probes until ctx is done.
+func (p *Prober) Run(ctx context.Context) {
+	failures := 0
+	for ctx.Err() == nil {
+		status, _ := p.Status()
+		if status != Unhealthy {
+			err := p.policy.DoContext(ctx, p.probe)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				var exceeded *retry.ErrMaxAttemptExceeded
+				if errors.As(err, &exceeded) {
+					err = exceeded.Err
+				}
+				failures = 0
+				p.set(Unhealthy, err)
+				continue
+			}
+			p.set(Healthy, nil)
+			if !sleep(ctx, p.interval) {
+				return
+			}
+			continue
+		}
+
+		failures++
+		if !sleep(ctx, p.policy.Delay(failures)) {
+			return
+		}
+		err := p.probe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			p.set(Healthy, nil)
+		} else {
+			p.set(Unhealthy, err)
+		}
+	}
+}
+
+func (p *Prober) set(status Status, err error) {
+	p.mu.Lock()
+	from := p.status
+	p.status = status
+	p.lastErr = err
+	p.mu.Unlock()
+	if from != status && p.onChange != nil {
+		p.onChange(Transition{From: from, To: status, Err: err, Time: time.Now()})
+	}
+}
+
+// sleep waits for d and reports whether ctx is still alive.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
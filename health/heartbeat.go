@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// Heartbeat sends heartbeats every interval, e.g. to renew a lease or keep a session alive.
+// A failed heartbeat is retried by the policy; once the policy gives up the heartbeat is lost and
+// is retried with the growing delays of the policy until it goes through again, after which
+// the policy starts over. Liveness transitions are reported as Healthy and Unhealthy.
+type Heartbeat struct {
+	prober *Prober
+}
+
+// NewHeartbeat creates a Heartbeat. onChange is called on every liveness transition and may be nil.
+func NewHeartbeat(send func(context.Context) error, policy retry.Retry, interval time.Duration, onChange func(Transition)) *Heartbeat {
+	return &Heartbeat{
+		prober: NewProber(send, policy, interval, onChange),
+	}
+}
+
+// Run sends heartbeats until ctx is done.
+func (h *Heartbeat) Run(ctx context.Context) {
+	h.prober.Run(ctx)
+}
+
+// Alive reports whether the last heartbeat went through.
+func (h *Heartbeat) Alive() bool {
+	status, _ := h.prober.Status()
+	return status == Healthy
+}
+
+// Err returns the error of the last failed heartbeat while it is lost, or nil.
+func (h *Heartbeat) Err() error {
+	_, err := h.prober.Status()
+	return err
+}
@@ -0,0 +1,113 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Hedge calls f, and calls it again concurrently whenever delay passes without an answer, to cut the
+// tail latency of an operation whose attempts are sometimes slow rather than failing. The first success
+// returns, and the context of the other attempts in flight is canceled. An attempt failing with an
+// error the policy retries leaves the others running; if none is left, the next attempt is made after
+// the delay of the policy, or the one hinted by the error. An error the policy does not retry returns
+// at once, canceling the others.
+//
+// At most the max attempts of the policy are made in total, and ErrMaxAttemptExceeded returns once
+// they all failed. ErrAborted returns when ctx is done first, and ErrInvalidConfig if the policy is
+// invalid. Only the predicate, the attempt limit, the delays and the clock of the policy apply.
+// f must be safe for concurrent use, and should return soon once its context is canceled.
+func Hedge(ctx context.Context, policy Retry, delay time.Duration, f func(context.Context) error) error {
+	r := policy
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if delay <= 0 {
+		panic("delay must be greater than 0")
+	}
+	maxAttempt := r.maxAttempt
+	if r.unlimited {
+		maxAttempt = math.MaxInt
+	}
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		attempt int
+		err     error
+		took    time.Duration
+	}
+	results := make(chan result)
+	launch := func(attempt int) {
+		go func() {
+			start := r.now()
+			err := f(hedgeCtx)
+			select {
+			case results <- result{attempt: attempt, err: err, took: r.now().Sub(start)}:
+			case <-hedgeCtx.Done():
+			}
+		}()
+	}
+
+	hist := history{limit: r.historyLimit}
+	begin := r.now()
+	var slept time.Duration
+	var lastErr error
+	var pending time.Duration // the delay of the policy the timer is set for, if no attempt is running
+	launched, running, failures := 1, 1, 0
+	launch(1)
+	timer := r.newTimer(delay)
+	defer func() { timer.Stop() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return &ErrAborted{Cause: ctx.Err(), Err: lastErr, Attempts: hist.attempts(), Dropped: hist.dropped}
+		case <-timer.C():
+			if launched == maxAttempt {
+				continue
+			}
+			if running == 0 {
+				slept += pending
+				hist.setDelay(pending)
+			}
+			launched++
+			running++
+			launch(launched)
+			timer = r.newTimer(delay)
+		case res := <-results:
+			running--
+			if res.err == nil {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return &ErrAborted{Cause: err, Err: res.err, Attempts: hist.attempts(), Dropped: hist.dropped}
+			}
+			if !r.retries(res.err) {
+				return unmark(res.err)
+			}
+			lastErr = res.err
+			failures++
+			hist.add(AttemptError{Attempt: res.attempt, Time: r.now(), Err: res.err, Duration: res.took})
+			if running > 0 {
+				continue
+			}
+			if launched == maxAttempt {
+				return &ErrMaxAttemptExceeded{
+					Err:          lastErr,
+					Attempts:     hist.attempts(),
+					Dropped:      hist.dropped,
+					AttemptsMade: launched,
+					Elapsed:      r.now().Sub(begin),
+					Slept:        slept,
+				}
+			}
+			wait := hintedDelay(res.err)
+			if wait <= 0 {
+				wait = r.Delay(failures)
+			}
+			pending = wait
+			timer.Stop()
+			timer = r.newTimer(wait)
+		}
+	}
+}
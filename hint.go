@@ -0,0 +1,23 @@
+package retry
+
+import "time"
+
+// DelayHinter is implemented by errors telling how long to wait before retrying, e.g. from
+// the Retry-After header of an HTTP 429 response or the RetryInfo of a gRPC status.
+// When the error of an attempt, or any error it wraps, implements DelayHinter with a positive
+// RetryAfter, Do sleeps exactly that long instead of the computed delay.
+type DelayHinter interface {
+	RetryAfter() time.Duration
+}
+
+// hintedDelay returns the delay hinted by err, or 0 if there is none.
+func hintedDelay(err error) time.Duration {
+	found := findError(err, func(err error) bool {
+		_, ok := err.(DelayHinter)
+		return ok
+	})
+	if found == nil {
+		return 0
+	}
+	return found.(DelayHinter).RetryAfter()
+}
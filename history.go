@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistoryLimit is the default number of attempt errors kept at each end of the attempt history.
+const DefaultHistoryLimit = 64
+
+// history keeps the first and the last limit attempt errors, and counts the ones dropped in between,
+// so a long running retry loop does not grow its memory without bound.
+type history struct {
+	limit   int
+	head    []AttemptError
+	tail    []AttemptError // ring buffer once it reaches limit
+	next    int            // the oldest entry of tail when it is full
+	dropped int
+	last    *AttemptError // the entry added last
+}
+
+// maxPooledHistory is the capacity above which a history is not put back to the pool.
+const maxPooledHistory = 4 * DefaultHistoryLimit
+
+var historyPool = sync.Pool{
+	New: func() any {
+		return &history{}
+	},
+}
+
+// acquireHistory takes a history from the pool, so steady retrying does not allocate the records anew.
+func acquireHistory(limit int) *history {
+	h := historyPool.Get().(*history)
+	h.limit = limit
+	return h
+}
+
+// release clears h and puts it back to the pool. h must not be used afterwards, and must not be
+// released once its attempts were handed out. release is a no-op on a nil history.
+func (h *history) release() {
+	if h == nil || cap(h.head) > maxPooledHistory || cap(h.tail) > maxPooledHistory {
+		return
+	}
+	for i := range h.head {
+		h.head[i] = AttemptError{}
+	}
+	for i := range h.tail {
+		h.tail[i] = AttemptError{}
+	}
+	*h = history{head: h.head[:0], tail: h.tail[:0]}
+	historyPool.Put(h)
+}
+
+func (h *history) add(a AttemptError) {
+	if h.limit <= 0 || len(h.head) < h.limit {
+		h.head = append(h.head, a)
+		h.last = &h.head[len(h.head)-1]
+		return
+	}
+	if len(h.tail) < h.limit {
+		h.tail = append(h.tail, a)
+		h.last = &h.tail[len(h.tail)-1]
+		return
+	}
+	h.tail[h.next] = a
+	h.last = &h.tail[h.next]
+	h.next = (h.next + 1) % h.limit
+	h.dropped++
+}
+
+// setDelay records the delay slept after the attempt added last.
+func (h *history) setDelay(d time.Duration) {
+	if h.last != nil {
+		h.last.Delay = d
+	}
+}
+
+// attempts returns the kept attempt errors in order. It returns nil on a nil history.
+func (h *history) attempts() []AttemptError {
+	if h == nil {
+		return nil
+	}
+	if len(h.tail) == 0 {
+		return h.head
+	}
+	result := make([]AttemptError, 0, len(h.head)+len(h.tail))
+	result = append(result, h.head...)
+	result = append(result, h.tail[h.next:]...)
+	return append(result, h.tail[:h.next]...)
+}
+
+// droppedCount returns the number of attempt errors left out. It returns 0 on a nil history.
+func (h *history) droppedCount() int {
+	if h == nil {
+		return 0
+	}
+	return h.dropped
+}
@@ -0,0 +1,123 @@
+// Package idempotency generates, persists and checks idempotency keys, so a write can be retried
+// safely: the key identifies the operation across attempts, and the store tells whether an attempt
+// which reported an error took effect anyway.
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Header is the HTTP header conventionally carrying the idempotency key.
+const Header = "Idempotency-Key"
+
+// Store persists idempotency keys and the state of the operations they guard.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Reserve records key as in progress for ttl. It returns false if key is already known.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Complete marks the operation of key as done.
+	Complete(ctx context.Context, key string) error
+	// Done reports whether the operation of key is done.
+	Done(ctx context.Context, key string) (bool, error)
+	// Release forgets key, so the operation may be started again.
+	Release(ctx context.Context, key string) error
+}
+
+// NewKey returns a random key in the format of a version 4 UUID.
+func NewKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("idempotency: crypto/rand failed: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type keyContextKey struct{}
+
+// WithKey returns a copy of ctx carrying key.
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// KeyFrom returns the key carried by ctx, if any.
+func KeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(keyContextKey{}).(string)
+	return key, ok
+}
+
+// Guard returns a function for retry.Retry.WithIdempotencyGuard which skips the retry once
+// the operation of key is done in store. Errors of the store are treated as not done.
+func Guard(store Store, key string) func(ctx context.Context, err error) bool {
+	return func(ctx context.Context, err error) bool {
+		done, storeErr := store.Done(ctx, key)
+		return storeErr == nil && done
+	}
+}
+
+// MemoryStore is a Store in memory, for tests and single instance services.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	done    bool
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+// Reserve implements Store.
+func (s *MemoryStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{expires: now.Add(ttl)}
+	return true, nil
+}
+
+// Complete implements Store. Completing an unknown or expired key is a no-op.
+func (s *MemoryStore) Complete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && time.Now().Before(e.expires) {
+		e.done = true
+		s.entries[key] = e
+	}
+	return nil
+}
+
+// Done implements Store.
+func (s *MemoryStore) Done(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return false, nil
+	}
+	if !time.Now().Before(e.expires) {
+		delete(s.entries, key)
+		return false, nil
+	}
+	return e.done, nil
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
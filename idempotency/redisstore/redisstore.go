@@ -0,0 +1,63 @@
+// Package redisstore implements idempotency.Store on Redis.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bluexlab/retry-go/idempotency"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	pending = "pending"
+	done    = "done"
+)
+
+// Store is an idempotency.Store keeping every key under a prefix in Redis.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ idempotency.Store = (*Store)(nil)
+
+// New creates a Store on client, prefixing every key with prefix.
+func New(client redis.UniversalClient, prefix string) *Store {
+	return &Store{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Reserve implements idempotency.Store.
+func (s *Store) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key, pending, ttl).Result()
+}
+
+// Complete implements idempotency.Store. Completing an unknown or expired key is a no-op.
+func (s *Store) Complete(ctx context.Context, key string) error {
+	err := s.client.SetArgs(ctx, s.prefix+key, done, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// Done implements idempotency.Store.
+func (s *Store) Done(ctx context.Context, key string) (bool, error) {
+	v, err := s.client.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return v == done, nil
+}
+
+// Release implements idempotency.Store.
+func (s *Store) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
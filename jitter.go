@@ -0,0 +1,52 @@
+package retry
+
+import "fmt"
+
+// Jitter is the way delays are randomized, so clients failing together do not retry in lockstep.
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+type Jitter int
+
+const (
+	// FullJitter sleeps a random delay between 0 and the nominal delay.
+	FullJitter Jitter = iota
+	// EqualJitter sleeps at least half of the nominal delay, and a random delay up to it.
+	EqualJitter
+	// DecorrelatedJitter sleeps a random delay between initDelay and three times the previous
+	// delay, capped by maxDelay, regardless of the number of retries.
+	DecorrelatedJitter
+	// NoJitter sleeps exactly the nominal delays.
+	NoJitter
+)
+
+func (j Jitter) String() string {
+	switch j {
+	case FullJitter:
+		return "full"
+	case EqualJitter:
+		return "equal"
+	case DecorrelatedJitter:
+		return "decorrelated"
+	case NoJitter:
+		return "none"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes j as its name, e.g. "full", for configuration files.
+func (j Jitter) MarshalText() ([]byte, error) {
+	if j < FullJitter || j > NoJitter {
+		return nil, fmt.Errorf("unknown jitter %d", int(j))
+	}
+	return []byte(j.String()), nil
+}
+
+// UnmarshalText decodes a name of a jitter, as encoded by MarshalText.
+func (j *Jitter) UnmarshalText(text []byte) error {
+	for k := FullJitter; k <= NoJitter; k++ {
+		if k.String() == string(text) {
+			*j = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown jitter %q", text)
+}
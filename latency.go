@@ -0,0 +1,48 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// errPredictedOverrun is the abort cause when the next attempt is predicted to end after the deadline.
+var errPredictedOverrun = fmt.Errorf("next attempt predicted to overrun the deadline: %w", context.DeadlineExceeded)
+
+// latencyEstimator keeps a smoothed estimate of the attempt duration, like the smoothed RTT of TCP.
+// It is safe for concurrent use.
+type latencyEstimator struct {
+	avg atomic.Int64 // time.Duration, 0 if no attempt has been observed yet.
+}
+
+func (l *latencyEstimator) observe(d time.Duration) {
+	if d <= 0 {
+		d = 1
+	}
+	for {
+		old := l.avg.Load()
+		next := int64(d)
+		if old != 0 {
+			next = old + (int64(d)-old)/8
+		}
+		if l.avg.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// estimate returns the smoothed attempt duration, or 0 if unknown.
+func (l *latencyEstimator) estimate() time.Duration {
+	return time.Duration(l.avg.Load())
+}
+
+// doomed reports whether an attempt started after delay is predicted to end after the deadline of ctx.
+func (l *latencyEstimator) doomed(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return false
+	}
+	estimate := l.estimate()
+	return estimate > 0 && time.Now().Add(delay+estimate).After(deadline)
+}
@@ -0,0 +1,30 @@
+package retry
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger receives the records of the retry loop, so any logging library can be plugged in with
+// WithCustomLogger. keyvals alternate keys, which are strings, and values, like slog.Logger.Log:
+// "attempt" and "attempts" are ints, "error" is an error, and "delay" and "elapsed" are time.Duration.
+// The adapters in retryzap and retrylogrus plug in zap and logrus.
+type Logger interface {
+	// Debug logs the routine records, such as a retry.
+	Debug(ctx context.Context, msg string, keyvals ...any)
+	// Warn logs the records worth attention, such as the exhaustion of the attempts.
+	Warn(ctx context.Context, msg string, keyvals ...any)
+}
+
+// slogLogger is the Logger of a slog.Logger.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Debug(ctx context.Context, msg string, keyvals ...any) {
+	s.l.Log(ctx, slog.LevelDebug, msg, keyvals...)
+}
+
+func (s slogLogger) Warn(ctx context.Context, msg string, keyvals ...any) {
+	s.l.Log(ctx, slog.LevelWarn, msg, keyvals...)
+}
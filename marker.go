@@ -0,0 +1,101 @@
+package retry
+
+// Unrecoverable marks err as not to be retried, whatever shouldRetry says about it, so a function
+// can stop the retrying when only it knows the failure is fatal. Do returns err itself, unmarked.
+// Unrecoverable returns nil if err is nil.
+func Unrecoverable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &unrecoverableError{err}
+}
+
+// IsUnrecoverable reports whether err, or any error it wraps, is marked by Unrecoverable.
+func IsUnrecoverable(err error) bool {
+	return findError(err, func(err error) bool {
+		_, ok := err.(*unrecoverableError)
+		return ok
+	}) != nil
+}
+
+// MarkRetryable marks err as to be retried, whatever shouldRetry says about it, e.g. when
+// shouldRetry is a third-party predicate which cannot be taught about err. Unrecoverable wins
+// over MarkRetryable if err carries both marks. MarkRetryable returns nil if err is nil.
+func MarkRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err}
+}
+
+// IsMarkedRetryable reports whether err, or any error it wraps, is marked by MarkRetryable.
+func IsMarkedRetryable(err error) bool {
+	return findError(err, func(err error) bool {
+		_, ok := err.(*retryableError)
+		return ok
+	}) != nil
+}
+
+// findError returns the first error in the tree of err, in the order of errors.As, matching match,
+// or nil if there is none. Unlike errors.As, it does not allocate, so it costs nothing to retrying.
+func findError(err error, match func(error) bool) error {
+	for err != nil {
+		if match(err) {
+			return err
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				if found := findError(err, match); found != nil {
+					return found
+				}
+			}
+			return nil
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+type unrecoverableError struct {
+	err error
+}
+
+func (e *unrecoverableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unrecoverableError) Unwrap() error {
+	return e.err
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// retries reports whether r retries err, honoring the marks on it before asking shouldRetry.
+func (r Retry) retries(err error) bool {
+	if IsUnrecoverable(err) {
+		return false
+	}
+	return IsMarkedRetryable(err) || r.shouldRetry(err)
+}
+
+// unmark strips the mark of Unrecoverable from err, if err is marked directly.
+func unmark(err error) error {
+	if u, ok := err.(*unrecoverableError); ok {
+		return u.err
+	}
+	return err
+}
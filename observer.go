@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// EventKind is the kind of an Event.
+type EventKind int
+
+const (
+	// AttemptStarted is sent before every attempt.
+	AttemptStarted EventKind = iota
+	// AttemptFailed is sent after every attempt which failed, retried or not.
+	AttemptFailed
+	// Sleeping is sent before waiting for the delay until the next attempt.
+	Sleeping
+	// Succeeded is sent after the attempt which succeeded.
+	Succeeded
+	// Exhausted is sent when the attempts are exhausted.
+	Exhausted
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case AttemptStarted:
+		return "attempt started"
+	case AttemptFailed:
+		return "attempt failed"
+	case Sleeping:
+		return "sleeping"
+	case Succeeded:
+		return "succeeded"
+	case Exhausted:
+		return "exhausted"
+	}
+	return "unknown"
+}
+
+// Event is an event of the retry loop.
+type Event struct {
+	Kind     EventKind
+	Attempt  int           // the 1-based attempt the event is about; for Exhausted, the attempts made
+	Err      error         // the error of the attempt, for AttemptFailed and Exhausted
+	Duration time.Duration // how long the attempt took, for AttemptFailed and Succeeded
+	Delay    time.Duration // the delay until the next attempt, for Sleeping
+}
+
+// Observer receives the events of the retry loop, e.g. to feed a monitoring pipeline.
+// Observe is called synchronously by the loop, so it should be quick.
+type Observer interface {
+	Observe(ctx context.Context, e Event)
+}
+
+// ObserverFunc is an Observer calling a function.
+type ObserverFunc func(ctx context.Context, e Event)
+
+// Observe calls f.
+func (f ObserverFunc) Observe(ctx context.Context, e Event) {
+	f(ctx, e)
+}
@@ -0,0 +1,162 @@
+package retry
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The backoff types of a Policy.
+const (
+	// BackoffExponential doubles InitialDelay on every retry up to MaxDelay. It is the default.
+	BackoffExponential = "exponential"
+	// BackoffConstant waits InitialDelay before every retry.
+	BackoffConstant = "constant"
+	// BackoffLinear waits InitialDelay before the first retry, and Step longer before every
+	// further one, up to MaxDelay.
+	BackoffLinear = "linear"
+)
+
+// Policy is the configuration of a Retry, so it may live in the configuration files of a service
+// instead of being hard-coded. It is encoded in JSON with the durations as strings parsed by
+// time.ParseDuration, e.g.
+//
+//	{"maxAttempts": 5, "initialDelay": "100ms", "maxDelay": "5s", "backoff": "exponential", "jitter": "full"}
+//
+// and bears yaml tags for the YAML decoders handling time.Duration, such as gopkg.in/yaml.v3.
+// The zero values of the optional fields leave the defaults of New. FromConfig builds the Retry.
+type Policy struct {
+	MaxAttempts    int           `json:"maxAttempts" yaml:"maxAttempts"`
+	Unlimited      bool          `json:"unlimited,omitempty" yaml:"unlimited,omitempty"` // retry until success, see WithUnlimitedAttempts
+	InitialDelay   time.Duration `json:"initialDelay" yaml:"initialDelay"`
+	MaxDelay       time.Duration `json:"maxDelay" yaml:"maxDelay"`
+	Backoff        string        `json:"backoff,omitempty" yaml:"backoff,omitempty"` // BackoffExponential if empty
+	Step           time.Duration `json:"step,omitempty" yaml:"step,omitempty"`       // of BackoffLinear
+	Jitter         Jitter        `json:"jitter" yaml:"jitter"`
+	AttemptTimeout time.Duration `json:"attemptTimeout,omitempty" yaml:"attemptTimeout,omitempty"`
+	MaxElapsedTime time.Duration `json:"maxElapsedTime,omitempty" yaml:"maxElapsedTime,omitempty"`
+}
+
+// policyJSON is the JSON encoding of Policy, with the durations as strings.
+type policyJSON struct {
+	MaxAttempts    int          `json:"maxAttempts"`
+	Unlimited      bool         `json:"unlimited,omitempty"`
+	InitialDelay   jsonDuration `json:"initialDelay"`
+	MaxDelay       jsonDuration `json:"maxDelay"`
+	Backoff        string       `json:"backoff,omitempty"`
+	Step           jsonDuration `json:"step,omitempty"`
+	Jitter         Jitter       `json:"jitter"`
+	AttemptTimeout jsonDuration `json:"attemptTimeout,omitempty"`
+	MaxElapsedTime jsonDuration `json:"maxElapsedTime,omitempty"`
+}
+
+// MarshalJSON encodes p with the durations as strings, e.g. "1.5s".
+func (p Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(policyJSON{
+		MaxAttempts:    p.MaxAttempts,
+		Unlimited:      p.Unlimited,
+		InitialDelay:   jsonDuration(p.InitialDelay),
+		MaxDelay:       jsonDuration(p.MaxDelay),
+		Backoff:        p.Backoff,
+		Step:           jsonDuration(p.Step),
+		Jitter:         p.Jitter,
+		AttemptTimeout: jsonDuration(p.AttemptTimeout),
+		MaxElapsedTime: jsonDuration(p.MaxElapsedTime),
+	})
+}
+
+// UnmarshalJSON decodes p as encoded by MarshalJSON.
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	var v policyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Policy{
+		MaxAttempts:    v.MaxAttempts,
+		Unlimited:      v.Unlimited,
+		InitialDelay:   time.Duration(v.InitialDelay),
+		MaxDelay:       time.Duration(v.MaxDelay),
+		Backoff:        v.Backoff,
+		Step:           time.Duration(v.Step),
+		Jitter:         v.Jitter,
+		AttemptTimeout: time.Duration(v.AttemptTimeout),
+		MaxElapsedTime: time.Duration(v.MaxElapsedTime),
+	}
+	return nil
+}
+
+// jsonDuration is a time.Duration encoded in JSON as a string parsed by time.ParseDuration.
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *jsonDuration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(v)
+	return nil
+}
+
+// Validate returns ErrInvalidConfig if p is invalid: no attempt to make, negative durations,
+// an initial delay longer than the max delay, or an unknown backoff type or jitter.
+func (p Policy) Validate() error {
+	switch {
+	case p.MaxAttempts <= 0 && !p.Unlimited:
+		return &ErrInvalidConfig{Param: "maxAttempts", Reason: "must be greater than 0"}
+	case p.InitialDelay < 0:
+		return &ErrInvalidConfig{Param: "initialDelay", Reason: "must not be negative"}
+	case p.MaxDelay < 0:
+		return &ErrInvalidConfig{Param: "maxDelay", Reason: "must not be negative"}
+	case p.InitialDelay > p.MaxDelay:
+		return &ErrInvalidConfig{Param: "initialDelay", Reason: "must not be greater than maxDelay"}
+	case p.Step < 0:
+		return &ErrInvalidConfig{Param: "step", Reason: "must not be negative"}
+	case p.Jitter < FullJitter || p.Jitter > NoJitter:
+		return &ErrInvalidConfig{Param: "jitter", Reason: "is unknown"}
+	case p.AttemptTimeout < 0:
+		return &ErrInvalidConfig{Param: "attemptTimeout", Reason: "must not be negative"}
+	case p.MaxElapsedTime < 0:
+		return &ErrInvalidConfig{Param: "maxElapsedTime", Reason: "must not be negative"}
+	case p.Backoff != "" && p.Backoff != BackoffExponential && p.Backoff != BackoffConstant && p.Backoff != BackoffLinear:
+		return &ErrInvalidConfig{Param: "backoff", Reason: "is unknown"}
+	}
+	return nil
+}
+
+// FromConfig builds a Retry from cfg, retrying the errors shouldRetry accepts, which cannot be
+// configured. It returns ErrInvalidConfig if cfg is invalid, see Validate, or shouldRetry is nil.
+func FromConfig(cfg Policy, shouldRetry func(error) bool) (Retry, error) {
+	if shouldRetry == nil {
+		return Retry{}, &ErrInvalidConfig{Param: "shouldRetry", Reason: "must not be nil"}
+	}
+	if err := cfg.Validate(); err != nil {
+		return Retry{}, err
+	}
+	r := NewWithDurations(shouldRetry, cfg.MaxAttempts, cfg.InitialDelay, cfg.MaxDelay).WithJitter(cfg.Jitter)
+	switch cfg.Backoff {
+	case "", BackoffExponential:
+	case BackoffConstant:
+		r = r.WithBackoff(ConstantBackoff(cfg.InitialDelay))
+	case BackoffLinear:
+		linear := LinearBackoff(cfg.InitialDelay, cfg.Step)
+		r = r.WithBackoff(func(n int) time.Duration {
+			if d := linear(n); d < cfg.MaxDelay {
+				return d
+			}
+			return cfg.MaxDelay
+		})
+	}
+	if cfg.Unlimited {
+		r = r.WithUnlimitedAttempts()
+	}
+	if cfg.AttemptTimeout > 0 {
+		r = r.WithAttemptTimeout(cfg.AttemptTimeout)
+	}
+	if cfg.MaxElapsedTime > 0 {
+		r = r.WithMaxElapsedTime(cfg.MaxElapsedTime)
+	}
+	return r, nil
+}
@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQuotaExhausted is the abort cause when a RetryQuota cannot afford a retry.
+var ErrQuotaExhausted = errors.New("retry quota exhausted")
+
+// Default costs of a RetryQuota, the same as the standard retry mode of the AWS SDKs.
+const (
+	DefaultRetryCost   = 5
+	DefaultTimeoutCost = 10
+)
+
+// RetryQuota throttles retries under sustained failure, like the retry quota of the standard retry
+// mode of the AWS SDKs. Every retry costs retryCost units, or timeoutCost after a timeout, and is
+// skipped when the quota cannot afford it, in which case Do gives up with ErrAborted matching
+// ErrQuotaExhausted. Successful calls replenish the quota: by one unit if they made a single attempt,
+// by the cost of their last retry otherwise. While the dependency keeps failing, the quota drains and
+// retries stop; once it recovers, the quota fills again. It is safe for concurrent use by many Retry instances.
+type RetryQuota struct {
+	capacity    int
+	retryCost   int
+	timeoutCost int
+
+	mu        sync.Mutex
+	available int
+
+	rejected atomic.Uint64
+}
+
+// QuotaStats is a snapshot of the state of a RetryQuota.
+type QuotaStats struct {
+	Available int    // units left
+	Capacity  int    // units the quota holds when full
+	Rejected  uint64 // retries skipped for lack of units
+}
+
+// NewRetryQuota creates a full RetryQuota holding capacity units, 500 in the AWS SDKs,
+// with the default costs.
+func NewRetryQuota(capacity int) *RetryQuota {
+	return &RetryQuota{
+		capacity:    capacity,
+		retryCost:   DefaultRetryCost,
+		timeoutCost: DefaultTimeoutCost,
+		available:   capacity,
+	}
+}
+
+// WithCosts sets the costs of a retry after a timeout and after any other error.
+// It must be called before a quota is used.
+func (q *RetryQuota) WithCosts(retryCost int, timeoutCost int) *RetryQuota {
+	q.retryCost = retryCost
+	q.timeoutCost = timeoutCost
+	return q
+}
+
+// Stats returns a snapshot of the state.
+func (q *RetryQuota) Stats() QuotaStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QuotaStats{Available: q.available, Capacity: q.capacity, Rejected: q.rejected.Load()}
+}
+
+// acquire takes the cost of a retry after err, returning it, unless the quota cannot afford it.
+func (q *RetryQuota) acquire(err error) (int, bool) {
+	cost := q.retryCost
+	if isTimeout(err) {
+		cost = q.timeoutCost
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if cost > q.available {
+		q.rejected.Add(1)
+		return 0, false
+	}
+	q.available -= cost
+	return cost, true
+}
+
+// release replenishes the quota after a successful call, whose last retry cost cost units,
+// or 0 if it made a single attempt.
+func (q *RetryQuota) release(cost int) {
+	if cost == 0 {
+		cost = 1
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.available += cost
+	if q.available > q.capacity {
+		q.available = q.capacity
+	}
+}
+
+// isTimeout reports whether err is a timeout, which retrying costs more.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,49 @@
+package retry
+
+import "context"
+
+// RaceDo calls the alternatives fns concurrently, e.g. the same request to several regions, and
+// returns as soon as one succeeds, canceling the context of the others. A round where every one
+// fails is a failed attempt of policy, which retries the whole round according to its schedule, so
+// the alternatives share a single attempt limit and backoff. The round fails with the last error of
+// the alternatives the policy retries, or the first error if it retries none, which is what RaceDo
+// returns when the policy gives up. To try the alternatives one after another instead, use a
+// Failover with fns as the endpoints. fns must not be empty.
+func RaceDo(ctx context.Context, policy Retry, fns ...func(context.Context) error) error {
+	if len(fns) == 0 {
+		panic("fns must not be empty")
+	}
+	return policy.DoContext(ctx, func(ctx context.Context) error {
+		return race(ctx, policy, fns)
+	})
+}
+
+// race calls fns concurrently and returns nil once one succeeds, or the error of the round once all failed.
+func race(ctx context.Context, policy Retry, fns []func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan error, len(fns))
+	for _, f := range fns {
+		f := f
+		go func() {
+			results <- f(ctx)
+		}()
+	}
+	var first, retryable error
+	for range fns {
+		err := <-results
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+		if policy.retries(err) {
+			retryable = err
+		}
+	}
+	if retryable != nil {
+		return retryable
+	}
+	return first
+}
@@ -0,0 +1,72 @@
+package retry
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var seedCounter atomic.Int64
+
+// randPool holds the generators of the Retry instances without a source of their own.
+// A sync.Pool keeps them per processor, so concurrent retries do not contend on any lock.
+var randPool = sync.Pool{
+	New: func() any {
+		return rand.New(rand.NewSource(time.Now().UnixNano() + seedCounter.Add(1)))
+	},
+}
+
+// lockedRand is the random source of a Retry. Unless a source is supplied it draws from randPool,
+// otherwise it serializes the calls to the source with a lock, as a supplied source does not
+// need to be safe for concurrent use. It is safe for concurrent use.
+type lockedRand struct {
+	once sync.Once
+	mu   sync.Mutex
+	src  rand.Source
+	rand *rand.Rand
+}
+
+func (l *lockedRand) init() {
+	l.rand = rand.New(l.src)
+}
+
+// Float64 returns a number in [0.0,1.0). A nil l draws from randPool, as the zero Retry has no
+// lockedRand of its own.
+func (l *lockedRand) Float64() float64 {
+	if l == nil || l.src == nil {
+		r := randPool.Get().(*rand.Rand)
+		f := r.Float64()
+		randPool.Put(r)
+		return f
+	}
+	l.once.Do(l.init)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rand.Float64()
+}
+
+// CryptoRandSource returns a rand.Source backed by crypto/rand, for use with Retry.WithRandSource
+// when the timing of retries must not be predictable to the remote side.
+func CryptoRandSource() rand.Source {
+	return cryptoSource{}
+}
+
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("retry: crypto/rand failed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Seed is a no-op since crypto/rand cannot be seeded.
+func (cryptoSource) Seed(int64) {}
@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"context"
+	"net"
+)
+
+// ResolveFunc resolves target, in the form of "host:port", to the addresses to connect to.
+type ResolveFunc func(ctx context.Context, target string) ([]string, error)
+
+// ResolveDNS is the default ResolveFunc looking host up with net.DefaultResolver.
+// A target whose host is an IP address resolves to itself.
+func ResolveDNS(ctx context.Context, target string) ([]string, error) {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return []string{target}, nil
+	}
+	ips, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.JoinHostPort(ip, port)
+	}
+	return addrs, nil
+}
+
+// DoResolved is like DoContext, but resolves target anew before every attempt and calls f with
+// one of the addresses, rotating through them by attempt, so retries do not keep hitting a dead
+// address from a stale resolution. resolve defaults to ResolveDNS if nil.
+// A failed resolution counts as a failed attempt.
+func (r Retry) DoResolved(ctx context.Context, target string, resolve ResolveFunc, f func(ctx context.Context, addr string) error) error {
+	if resolve == nil {
+		resolve = ResolveDNS
+	}
+	attempt := 0
+	return r.DoContext(ctx, func(ctx context.Context) error {
+		attempt++
+		addrs, err := resolve(ctx, target)
+		if err != nil {
+			return err
+		}
+		if len(addrs) == 0 {
+			return &net.DNSError{Err: "no addresses", Name: target, IsNotFound: true}
+		}
+		return f(ctx, addrs[(attempt-1)%len(addrs)])
+	})
+}
@@ -0,0 +1,47 @@
+package retry
+
+import "context"
+
+// Retrier is the interface of Retry, for libraries to accept a retry policy without depending on
+// how it retries, and for tests and benchmarks to substitute one, such as NopRetrier, without
+// branching. DoValueWith provides the value variant for any Retrier.
+type Retrier interface {
+	Do(f func() error) error
+	DoWithAttempt(f func(attempt int) error) error
+	DoContext(ctx context.Context, f func(context.Context) error) error
+}
+
+var _ Retrier = Retry{}
+
+// NopRetrier is a Retrier calling the function exactly once, returning its error as is,
+// except for the mark of Unrecoverable, which is stripped like Retry does.
+type NopRetrier struct{}
+
+// Do calls f once.
+func (NopRetrier) Do(f func() error) error {
+	return unmark(f())
+}
+
+// DoWithAttempt calls f once, with attempt 1.
+func (NopRetrier) DoWithAttempt(f func(attempt int) error) error {
+	return unmark(f(1))
+}
+
+// DoContext calls f once with ctx, even if ctx is done.
+func (NopRetrier) DoContext(ctx context.Context, f func(context.Context) error) error {
+	return unmark(f(ctx))
+}
+
+// DoValueWith is like DoValue, but retries with any Retrier. With a Retry, it is DoValue.
+func DoValueWith[R any](ctx context.Context, r Retrier, f func(ctx context.Context) (R, error)) (R, error) {
+	if policy, ok := r.(Retry); ok {
+		return DoValue(ctx, policy, f)
+	}
+	var result R
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		var e error
+		result, e = f(ctx)
+		return e
+	})
+	return result, err
+}
@@ -1,7 +1,9 @@
 package retry
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
+	"math"
 	"math/rand"
 	"time"
 )
@@ -10,316 +12,1104 @@ import (
 type Retry struct {
 	shouldRetry func(error) bool
 	maxAttempt  int // max attemp
-	initDelay   int // ms
-	maxDelay    int // ms
-}
+	unlimited   bool
+	initDelay   time.Duration
+	maxDelay    time.Duration
 
-// ErrMaxAttemptExceeded wraps the original error when the max retry attempt exceeded.
-type ErrMaxAttemptExceeded struct {
-	Err error
-}
+	historyLimit int
+	rand         *lockedRand
+	wheel        *TimerWheel
+	clock        Clock
+	jitter       Jitter
+	backoff      Backoff
+	fixedRate    bool
 
-func (e *ErrMaxAttemptExceeded) Error() string {
-	return fmt.Sprintf("exceed max retry attempts. Original error: %v", e.Err.Error())
-}
+	attemptTimeout   time.Duration
+	fullDeadlineLast bool
+	sliceDeadline    bool
+	maxTotalSleep    time.Duration
+	maxElapsed       time.Duration
+	excludeMandated  bool
 
-func (e *ErrMaxAttemptExceeded) Unwrap() error {
-	return e.Err
+	idempotencyGuard func(context.Context, error) bool
+	compensate       func(context.Context, []AttemptError)
+	latency          *latencyEstimator
+	gate             *FailureRateGate
+	healthy          func(context.Context) bool
+	throttle         *AdaptiveThrottle
+	breaker          *CircuitBreaker
+	budget           *RetryBudget
+	quota            *RetryQuota
+	limiter          Limiter
+	slots            chan struct{}
+	logger           Logger
+	observer         Observer
+	stats            *stats
+	deadLetter       func(ctx context.Context, err error)
+	delayHook        func([]AttemptError) time.Duration
+	onRetry          func(attempt int, err error, nextDelay time.Duration)
+	beforeAttempt    func(attempt int)
+	afterAttempt     func(attempt int, err error, took time.Duration)
+	retryIfValue     any // func(R) bool, see WithRetryIfValue
 }
 
 // New creates a "Retry"
 // shouldRetry is a function to decide if a function should retry.
 // maxAttemp specifies the max attempts.
 // delay is the delay between retries. The unit is ms.
+// maxDelay caps every delay, so initDelay is lowered to maxDelay if it is larger.
 func New(shouldRetry func(error) bool, maxAttempt int, initDelay int, maxDelay int) Retry {
+	return NewWithDurations(shouldRetry, maxAttempt, msToDuration(initDelay), msToDuration(maxDelay))
+}
+
+// NewWithDurations is like New, but takes the delays as time.Duration,
+// so they may be shorter than a millisecond or as long as needed.
+func NewWithDurations(shouldRetry func(error) bool, maxAttempt int, initDelay time.Duration, maxDelay time.Duration) Retry {
 	return Retry{
 		shouldRetry: shouldRetry,
 		maxAttempt:  maxAttempt,
-		initDelay:   initDelay,
-		maxDelay:    maxDelay,
+
+		historyLimit: DefaultHistoryLimit,
+		rand:         &lockedRand{},
+	}.WithDelays(initDelay, maxDelay)
+}
+
+// msToDuration converts ms to a time.Duration, saturating instead of overflowing.
+func msToDuration(ms int) time.Duration {
+	const limit = int64(math.MaxInt64 / time.Millisecond)
+	if int64(ms) > limit {
+		return math.MaxInt64
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// WithDelays returns a copy of r with the delay before the first retry set to initDelay,
+// doubled on every retry up to maxDelay. Unlike New, it accepts delays shorter than a millisecond.
+// maxDelay caps every delay, so initDelay is lowered to maxDelay if it is larger.
+func (r Retry) WithDelays(initDelay time.Duration, maxDelay time.Duration) Retry {
+	if initDelay > maxDelay {
+		initDelay = maxDelay
+	}
+	r.initDelay = initDelay
+	r.maxDelay = maxDelay
+	return r
+}
+
+// WithMaxAttempts returns a copy of r making at most n attempts, i.e. n-1 retries,
+// undoing WithUnlimitedAttempts.
+func (r Retry) WithMaxAttempts(n int) Retry {
+	r.maxAttempt = n
+	r.unlimited = false
+	return r
+}
+
+// WithInitialDelay returns a copy of r with the delay before the first retry set to d, keeping
+// the max delay, which d is lowered to if it is larger.
+func (r Retry) WithInitialDelay(d time.Duration) Retry {
+	return r.WithDelays(d, r.maxDelay)
+}
+
+// WithMaxDelay returns a copy of r capping every delay to d, keeping the initial delay unless it is larger.
+func (r Retry) WithMaxDelay(d time.Duration) Retry {
+	return r.WithDelays(r.initDelay, d)
+}
+
+// WithRetryIf returns a copy of r retrying the errors retryIf accepts instead of those shouldRetry did.
+// The marks of Unrecoverable and MarkRetryable still take precedence.
+func (r Retry) WithRetryIf(retryIf func(error) bool) Retry {
+	r.shouldRetry = retryIf
+	return r
+}
+
+// WithDelayHook returns a copy of r computing the delay before every retry with hook instead of
+// the exponential schedule. hook receives the attempt history so far, bounded by WithHistoryLimit,
+// with the errors, durations and delays of the failed attempts; the last one has no delay yet.
+// The delay returned is used as is, without jitter. hook must not keep attempts after it returns.
+func (r Retry) WithDelayHook(hook func(attempts []AttemptError) time.Duration) Retry {
+	r.delayHook = hook
+	return r
+}
+
+// WithDelayFunc returns a copy of r computing the delay before every retry with delay instead of
+// the exponential schedule, from the 1-based number and the error of the attempt which just failed,
+// e.g. to honor a hint the server returned. The delay returned is used as is, without jitter.
+func (r Retry) WithDelayFunc(delay func(attempt int, err error) time.Duration) Retry {
+	return r.WithDelayHook(func(attempts []AttemptError) time.Duration {
+		last := attempts[len(attempts)-1]
+		return delay(last.Attempt, last.Err)
+	})
+}
+
+// WithBackoff returns a copy of r taking the nominal delays from b instead of doubling
+// initDelay up to maxDelay. The delays are still randomized by the Jitter set,
+// except for DecorrelatedJitter which is based on initDelay and maxDelay alone.
+func (r Retry) WithBackoff(b Backoff) Retry {
+	r.backoff = b
+	return r
+}
+
+// WithNoJitter returns a copy of r sleeping exactly the nominal delays,
+// i.e. initDelay doubled on every retry up to maxDelay, without randomization.
+func (r Retry) WithNoJitter() Retry {
+	return r.WithJitter(NoJitter)
+}
+
+// WithJitter returns a copy of r randomizing the delays with j. The default is FullJitter.
+func (r Retry) WithJitter(j Jitter) Retry {
+	r.jitter = j
+	return r
+}
+
+// WithFixedRate returns a copy of r measuring every delay from the start of the previous attempt
+// instead of its end, so attempts start at a fixed cadence. An attempt taking longer than
+// the delay is followed by the next one immediately; attempts never overlap.
+func (r Retry) WithFixedRate() Retry {
+	r.fixedRate = true
+	return r
+}
+
+// WithAttemptTimeout returns a copy of r running every attempt of DoContext with a context
+// which times out after d, so a single hung attempt cannot use up the whole deadline.
+// The timeout never extends the deadline of the context passed to DoContext. An attempt which
+// times out typically returns context.DeadlineExceeded, so shouldRetry must accept it for
+// the attempt to be retried.
+func (r Retry) WithAttemptTimeout(d time.Duration) Retry {
+	r.attemptTimeout = d
+	return r
+}
+
+// WithFullDeadlineForLastAttempt returns a copy of r giving the last attempt all the time left
+// before the deadline of the context passed to DoContext, instead of the attempt timeout.
+// It has no effect if the context has no deadline.
+func (r Retry) WithFullDeadlineForLastAttempt() Retry {
+	r.fullDeadlineLast = true
+	return r
+}
+
+// WithDeadlineSlicing returns a copy of r running every attempt of DoContext with a timeout
+// of the time left before the deadline of the context divided by the attempts left,
+// recomputed before each attempt. If an attempt timeout is set too, the shorter one applies.
+// It has no effect if the context has no deadline.
+func (r Retry) WithDeadlineSlicing() Retry {
+	r.sliceDeadline = true
+	return r
+}
+
+// WithMaxTotalSleep returns a copy of r limiting the sum of the delays between attempts to d,
+// regardless of how long the attempts themselves take. The delay which would exceed d is shortened
+// to what is left, and once nothing is left Do gives up with ErrAborted matching ErrDeadlineExceeded.
+func (r Retry) WithMaxTotalSleep(d time.Duration) Retry {
+	r.maxTotalSleep = d
+	return r
+}
+
+// WithUnlimitedAttempts returns a copy of r retrying until an attempt succeeds, fails with
+// an error shouldRetry declines, or the context is done, whatever maxAttempt is.
+// WithMaxElapsedTime is the natural way to bound it. WithFullDeadlineForLastAttempt and
+// WithDeadlineSlicing have no effect, as there is no last attempt.
+func (r Retry) WithUnlimitedAttempts() Retry {
+	r.unlimited = true
+	return r
+}
+
+// WithMaxElapsedTime returns a copy of r limiting the whole retry loop, attempts and delays, to d.
+// The context passed to the attempts carries the deadline too, and once it passes Do gives up
+// with ErrAborted matching ErrDeadlineExceeded, even if attempts remain.
+func (r Retry) WithMaxElapsedTime(d time.Duration) Retry {
+	r.maxElapsed = d
+	return r
+}
+
+// WithExcludeMandatedWaits returns a copy of r leaving out of the limit of WithMaxElapsedTime the
+// waits r does not choose: those for the Limiter, and the delays hinted by the errors, e.g. by
+// Retry-After. The attempts and the backoff delays still count, so mandated waits cannot use up
+// the whole limit. The limit is then tracked by the loop with the Clock of r only, instead of a
+// deadline on the context: Do gives up before an attempt once it is used up, and at once rather
+// than sleep a delay which would exceed it, with ErrAborted matching ErrDeadlineExceeded. An attempt
+// in flight is not cut short by the limit; use WithAttemptTimeout for that.
+// It has no effect without WithMaxElapsedTime.
+func (r Retry) WithExcludeMandatedWaits() Retry {
+	r.excludeMandated = true
+	return r
+}
+
+// WithIdempotencyGuard returns a copy of r calling guard before every retry with the error of
+// the previous attempt. If guard reports that the previous attempt took effect despite the error,
+// e.g. a write committed before a timeout, the retry is skipped and Do returns nil.
+// Note the value variants such as Retry2 return zero values in that case.
+func (r Retry) WithIdempotencyGuard(guard func(ctx context.Context, err error) bool) Retry {
+	r.idempotencyGuard = guard
+	return r
+}
+
+// WithOnRetry returns a copy of r calling onRetry before sleeping for every retry, with the 1-based
+// number and the error of the attempt which just failed and the delay before the next one,
+// e.g. to log or count retries. onRetry is not called after the last attempt.
+func (r Retry) WithOnRetry(onRetry func(attempt int, err error, nextDelay time.Duration)) Retry {
+	r.onRetry = onRetry
+	return r
+}
+
+// WithBeforeAttempt returns a copy of r calling before with the 1-based number of every attempt
+// right before making it.
+func (r Retry) WithBeforeAttempt(before func(attempt int)) Retry {
+	r.beforeAttempt = before
+	return r
+}
+
+// WithAfterAttempt returns a copy of r calling after right after every attempt, with its 1-based
+// number, the error it returned if any, and how long it took.
+func (r Retry) WithAfterAttempt(after func(attempt int, err error, took time.Duration)) Retry {
+	r.afterAttempt = after
+	return r
+}
+
+// WithCompensation returns a copy of r calling compensate with the attempt history once all
+// the attempts failed, before Do returns ErrMaxAttemptExceeded, so partial effects of the failed
+// attempts can be undone or recorded, like the compensation of a saga.
+func (r Retry) WithCompensation(compensate func(ctx context.Context, attempts []AttemptError)) Retry {
+	r.compensate = compensate
+	return r
+}
+
+// WithLatencyPrediction returns a copy of r keeping a smoothed estimate of the attempt duration
+// across calls, and giving up with ErrAborted instead of starting a retry which is predicted to
+// end after the deadline of the context passed to DoContext. The error matches ErrDeadlineExceeded.
+func (r Retry) WithLatencyPrediction() Retry {
+	r.latency = &latencyEstimator{}
+	return r
+}
+
+// WithFailureRateGate returns a copy of r recording the outcome of every attempt in g, and giving up
+// with ErrAborted instead of retrying while g suspends retries. The error matches ErrRetrySuspended.
+// The same gate may be shared by many Retry instances.
+func (r Retry) WithFailureRateGate(g *FailureRateGate) Retry {
+	r.gate = g
+	return r
+}
+
+// WithHealthCheck returns a copy of r consulting healthy before every retry, and giving up with
+// ErrAborted instead of waiting through the rest of the schedule once it reports the dependency down.
+// The error matches ErrDependencyDown. healthy should be cheap, e.g. the state of a circuit breaker
+// or of a health.Prober.
+func (r Retry) WithHealthCheck(healthy func(context.Context) bool) Retry {
+	r.healthy = healthy
+	return r
+}
+
+// WithAdaptiveThrottle returns a copy of r recording the outcome of every attempt in a, and giving up
+// with ErrAborted when a holds back an attempt. The error matches ErrThrottled.
+// The same throttle may be shared by many Retry instances calling the same upstream.
+func (r Retry) WithAdaptiveThrottle(a *AdaptiveThrottle) Retry {
+	r.throttle = a
+	return r
+}
+
+// WithCircuitBreaker returns a copy of r running every operation through b: while b is open, Do
+// gives up with ErrAborted without making any attempt. The error matches ErrCircuitOpen.
+// The same breaker may be shared by many Retry instances calling the same dependency.
+func (r Retry) WithCircuitBreaker(b *CircuitBreaker) Retry {
+	r.breaker = b
+	return r
+}
+
+// WithRetryBudget returns a copy of r making every call deposit in b and every retry withdraw from it,
+// and giving up with ErrAborted instead of retrying when b is exhausted. The error matches ErrBudgetExhausted.
+// The same budget is meant to be shared by every Retry instance calling the same dependency.
+func (r Retry) WithRetryBudget(b *RetryBudget) Retry {
+	r.budget = b
+	return r
+}
+
+// WithRetryQuota returns a copy of r paying for every retry from q, and giving up with ErrAborted
+// instead of retrying when q cannot afford it. The error matches ErrQuotaExhausted. Successful calls
+// replenish q. The same quota is meant to be shared by every Retry instance calling the same dependency.
+func (r Retry) WithRetryQuota(q *RetryQuota) Retry {
+	r.quota = q
+	return r
+}
+
+// Limiter is a client-side rate limiter, such as *rate.Limiter of golang.org/x/time/rate.
+type Limiter interface {
+	// Wait blocks until an event is allowed, or returns an error if ctx is done first
+	// or the wait would outlast the deadline of ctx.
+	Wait(ctx context.Context) error
+}
+
+// WithLimiter returns a copy of r waiting for l before every attempt, the first one included,
+// so the rate limiting of the calls and the retry schedule live in one place. The wait comes on top
+// of the delay before a retry. If l fails to allow an attempt, Do gives up with ErrAborted wrapping
+// the error of l. The same limiter may be shared by many Retry instances.
+func (r Retry) WithLimiter(l Limiter) Retry {
+	r.limiter = l
+	return r
+}
+
+// WithMaxConcurrent returns a copy of r letting at most n calls of Do at once run their attempts
+// or sleep between them, among r and every copy made from the returned Retry. A call over the cap
+// waits for its turn, so an outage builds back-pressure on the callers instead of piling up
+// goroutines; if ctx is done first, it gives up with ErrAborted without making any attempt.
+func (r Retry) WithMaxConcurrent(n int) Retry {
+	if n <= 0 {
+		panic("n must be greater than 0")
 	}
+	r.slots = make(chan struct{}, n)
+	return r
+}
+
+// WithLogger returns a copy of r logging to l a debug record before every retry, with the attempt
+// which failed, its error and the delay until the next one, and a warning when the attempts are
+// exhausted, with their number, the last error and the time spent.
+func (r Retry) WithLogger(l *slog.Logger) Retry {
+	r.logger = slogLogger{l}
+	return r
+}
+
+// WithCustomLogger is like WithLogger, but logs to any Logger.
+func (r Retry) WithCustomLogger(l Logger) Retry {
+	r.logger = l
+	return r
+}
+
+// WithObserver returns a copy of r sending the events of the retry loop to o.
+func (r Retry) WithObserver(o Observer) Retry {
+	r.observer = o
+	return r
+}
+
+// WithStats returns a copy of r counting its calls, attempts and their outcomes, shared with every
+// copy made from the returned Retry, for Stats to report. The counting is off by default.
+func (r Retry) WithStats() Retry {
+	r.stats = &stats{}
+	return r
+}
+
+// Stats returns a snapshot of the statistics counted since WithStats, or zeros if it was not called.
+func (r Retry) Stats() Stats {
+	if r.stats == nil {
+		return Stats{}
+	}
+	return r.stats.snapshot()
+}
+
+// WithDeadLetter returns a copy of r handing the error of every call which gives up on a retryable
+// failure to f, e.g. to write the operation to a dead-letter topic or raise an alert, before Do returns
+// the error. A call gives up so when it exhausts its attempts, or is aborted for any reason but the
+// cancellation of its context, as a CircuitBreaker counts failures. The payload of the operation
+// can be carried in ctx.
+func (r Retry) WithDeadLetter(f func(ctx context.Context, err error)) Retry {
+	r.deadLetter = f
+	return r
+}
+
+// WithRandSource returns a copy of r drawing the jitter of delays from src instead of
+// a source of its own, e.g. a seeded source for reproducible delays.
+// src does not need to be safe for concurrent use.
+func (r Retry) WithRandSource(src rand.Source) Retry {
+	r.rand = &lockedRand{src: src}
+	return r
+}
+
+// WithClock returns a copy of r taking the time from c, to measure the attempts and to wait
+// between them. The timeouts of WithAttemptTimeout and WithMaxElapsedTime, which are context
+// deadlines, keep following the system clock. A Clock takes precedence over a TimerWheel.
+func (r Retry) WithClock(c Clock) Retry {
+	r.clock = c
+	return r
+}
+
+// WithTimerWheel returns a copy of r sleeping between retries on the shared wheel w
+// instead of a timer of its own.
+func (r Retry) WithTimerWheel(w *TimerWheel) Retry {
+	r.wheel = w
+	return r
+}
+
+// WithHistoryLimit returns a copy of r keeping only the first and the last n attempt errors
+// in ErrMaxAttemptExceeded and ErrAborted. The attempts in between are counted in Dropped.
+// n <= 0 keeps every attempt error.
+func (r Retry) WithHistoryLimit(n int) Retry {
+	r.historyLimit = n
+	return r
+}
+
+// maxDelayLimit is the largest delay NewChecked accepts. The unit is ms.
+const maxDelayLimit = int(24 * time.Hour / time.Millisecond)
+
+// NewChecked is like New, but returns ErrInvalidConfig instead of silently adjusting or
+// accepting parameters that would make the retry misbehave.
+func NewChecked(shouldRetry func(error) bool, maxAttempt int, initDelay int, maxDelay int) (Retry, error) {
+	switch {
+	case shouldRetry == nil:
+		return Retry{}, &ErrInvalidConfig{Param: "shouldRetry", Reason: "must not be nil"}
+	case maxAttempt <= 0:
+		return Retry{}, &ErrInvalidConfig{Param: "maxAttempt", Reason: "must be greater than 0"}
+	case initDelay < 0:
+		return Retry{}, &ErrInvalidConfig{Param: "initDelay", Reason: "must not be negative"}
+	case maxDelay < 0:
+		return Retry{}, &ErrInvalidConfig{Param: "maxDelay", Reason: "must not be negative"}
+	case initDelay > maxDelay:
+		return Retry{}, &ErrInvalidConfig{Param: "initDelay", Reason: "must not be greater than maxDelay"}
+	case maxDelay > maxDelayLimit:
+		return Retry{}, &ErrInvalidConfig{Param: "maxDelay", Reason: "must not be longer than 24h"}
+	}
+	return New(shouldRetry, maxAttempt, initDelay, maxDelay), nil
+}
+
+// Validate returns ErrInvalidConfig if r cannot retry: it has no shouldRetry predicate, no attempt
+// to make, or negative delays, e.g. a Retry built with New from unchecked parameters, or the zero
+// Retry. DoContext returns the same error instead of making any attempt.
+func (r Retry) Validate() error {
+	switch {
+	case r.shouldRetry == nil:
+		return &ErrInvalidConfig{Param: "shouldRetry", Reason: "must not be nil"}
+	case r.maxAttempt <= 0 && !r.unlimited:
+		return &ErrInvalidConfig{Param: "maxAttempt", Reason: "must be greater than 0"}
+	case r.initDelay < 0:
+		return &ErrInvalidConfig{Param: "initDelay", Reason: "must not be negative"}
+	case r.maxDelay < 0:
+		return &ErrInvalidConfig{Param: "maxDelay", Reason: "must not be negative"}
+	}
+	return nil
 }
 
 // Do calls the input function and check the result.
 // ErrMaxAttemptExceeded returns when maxAttamp exceeded.
 func (r Retry) Do(f func() error) error {
-	if r.maxAttempt <= 0 {
-		panic("maxAttemp must be greater than 0")
+	return r.DoContext(context.Background(), func(context.Context) error {
+		return f()
+	})
+}
+
+// DoWithAttempt is like Do, but passes the 1-based number of the attempt to f.
+func (r Retry) DoWithAttempt(f func(attempt int) error) error {
+	attempt := 0
+	return r.Do(func() error {
+		attempt++
+		return f(attempt)
+	})
+}
+
+// DoContext is like Do, but passes ctx to every attempt and stops retrying once ctx is done,
+// including in the middle of a delay.
+// ErrMaxAttemptExceeded returns when maxAttamp exceeded.
+// ErrAborted returns when ctx is canceled or its deadline passes before f succeeds.
+// ErrInvalidConfig returns, without any attempt, if r is invalid, see Validate.
+func (r Retry) DoContext(ctx context.Context, f func(context.Context) error) (err error) {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if r.stats != nil {
+		r.stats.calls.Add(1)
+		defer func() { r.stats.record(err) }()
+	}
+	if r.deadLetter != nil {
+		defer func() {
+			if failed, _ := operationFailed(err); failed {
+				r.deadLetter(ctx, err)
+			}
+		}()
+	}
+	if r.slots != nil {
+		select {
+		case r.slots <- struct{}{}:
+		case <-ctx.Done():
+			return &ErrAborted{Cause: ctx.Err()}
+		}
+		defer func() { <-r.slots }()
+	}
+	if r.breaker == nil {
+		return r.doContext(ctx, f)
+	}
+	if !r.breaker.allow() {
+		return &ErrAborted{Cause: ErrCircuitOpen}
+	}
+	err = r.doContext(ctx, f)
+	r.breaker.record(err)
+	return err
+}
+
+func (r Retry) doContext(ctx context.Context, f func(context.Context) error) error {
+	tracked := r.maxElapsed > 0 && r.excludeMandated // the limit is tracked by the loop
+	if r.maxElapsed > 0 && !tracked {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.maxElapsed)
+		defer cancel()
 	}
 	maxAttempt := r.maxAttempt
-	delay := r.initDelay
+	if r.unlimited {
+		maxAttempt = math.MaxInt
+	}
 	var lastErr error
+	var hist *history // acquired on the first failure
+	begin := r.now()
+	var slept time.Duration
+	var prev time.Duration     // the delay before the last retry
+	var guard *Guard           // entered on the first retry
+	var cost int               // paid from the quota for the last retry
+	var mandated time.Duration // waited for the limiter and hinted delays, if tracked
+	defer func() {
+		if guard != nil {
+			guard.leave()
+		}
+	}()
 	for i := 0; i < maxAttempt; i++ {
-		lastErr = f()
+		if err := ctx.Err(); err != nil {
+			return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if r.throttle != nil && !r.throttle.allow(i == 0) {
+			return &ErrAborted{Cause: ErrThrottled, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if r.limiter != nil {
+			var waited time.Time
+			if tracked {
+				waited = r.now()
+			}
+			if err := r.limiter.Wait(ctx); err != nil {
+				return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+			}
+			if tracked {
+				mandated += r.now().Sub(waited)
+			}
+		}
+		if tracked && r.elapsedLeft(begin, mandated) <= 0 {
+			return &ErrAborted{Cause: errElapsedExhausted, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if i == 0 && r.budget != nil {
+			r.budget.deposit()
+		}
+		attemptCtx, cancel := r.attemptContext(ctx, i+1, maxAttempt)
+		if r.beforeAttempt != nil {
+			r.beforeAttempt(i + 1)
+		}
+		if r.observer != nil {
+			r.observer.Observe(ctx, Event{Kind: AttemptStarted, Attempt: i + 1})
+		}
+		if r.stats != nil {
+			r.stats.attempts.Add(1)
+			if i > 0 {
+				r.stats.retries.Add(1)
+			}
+		}
+		start := r.now()
+		lastErr = f(attemptCtx)
+		took := r.now().Sub(start)
+		cancel()
+		if r.afterAttempt != nil {
+			r.afterAttempt(i+1, lastErr, took)
+		}
+		if r.observer != nil {
+			if lastErr == nil {
+				r.observer.Observe(ctx, Event{Kind: Succeeded, Attempt: i + 1, Duration: took})
+			} else {
+				r.observer.Observe(ctx, Event{Kind: AttemptFailed, Attempt: i + 1, Err: lastErr, Duration: took})
+			}
+		}
+		if r.latency != nil {
+			r.latency.observe(took)
+		}
+		if r.gate != nil {
+			r.gate.Record(lastErr != nil)
+		}
+		if r.throttle != nil {
+			r.throttle.Record(lastErr)
+		}
 		if lastErr == nil {
+			if r.quota != nil {
+				r.quota.release(cost)
+			}
+			hist.release()
+			return nil
+		}
+		if !r.retries(lastErr) {
+			hist.release()
+			return unmark(lastErr)
+		}
+		if hist == nil {
+			hist = acquireHistory(r.historyLimit)
+		}
+		hist.add(AttemptError{Attempt: i + 1, Time: r.now(), Err: lastErr, Duration: took})
+		if i == maxAttempt-1 {
+			// no point in sleeping when there is no attempt left.
+			break
+		}
+		if r.gate != nil && !r.gate.Allow() {
+			return &ErrAborted{Cause: ErrRetrySuspended, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if r.budget != nil && !r.budget.withdraw() {
+			return &ErrAborted{Cause: ErrBudgetExhausted, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if r.quota != nil {
+			var ok bool
+			if cost, ok = r.quota.acquire(lastErr); !ok {
+				return &ErrAborted{Cause: ErrQuotaExhausted, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+			}
+		}
+		if r.healthy != nil && !r.healthy(ctx) {
+			return &ErrAborted{Cause: ErrDependencyDown, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if g := GlobalGuard(); g != nil {
+			if guard == nil {
+				if err := g.enter(ctx); err != nil {
+					return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+				}
+				guard = g
+			}
+			if err := g.admit(ctx); err != nil {
+				return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+			}
+		}
+		wait := hintedDelay(lastErr)
+		hinted := wait > 0
+		if !hinted {
+			if r.delayHook != nil {
+				wait = r.delayHook(hist.attempts())
+			} else {
+				wait = r.delay(i+1, prev)
+			}
+			prev = wait
+			if r.fixedRate {
+				wait -= r.now().Sub(start)
+			}
+		} else {
+			prev = wait
+		}
+		if r.maxTotalSleep > 0 {
+			left := r.maxTotalSleep - slept
+			if left <= 0 {
+				return &ErrAborted{Cause: errSleepExhausted, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+			}
+			if wait > left {
+				wait = left
+			}
+		}
+		if r.latency != nil && r.latency.doomed(ctx, wait) {
+			return &ErrAborted{Cause: errPredictedOverrun, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if wait < 0 {
+			wait = 0
+		}
+		if tracked && !hinted && wait >= r.elapsedLeft(begin, mandated) {
+			return &ErrAborted{Cause: errElapsedExhausted, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if r.onRetry != nil {
+			r.onRetry(i+1, lastErr, wait)
+		}
+		if r.observer != nil {
+			r.observer.Observe(ctx, Event{Kind: Sleeping, Attempt: i + 1, Delay: wait})
+		}
+		if r.logger != nil {
+			r.logger.Debug(ctx, "retrying", "attempt", i+1, "error", lastErr, "delay", wait)
+		}
+		var sleeping time.Time
+		if tracked && hinted {
+			sleeping = r.now()
+		}
+		if err := r.sleep(ctx, wait); err != nil {
+			return &ErrAborted{Cause: err, Err: lastErr, Attempts: hist.attempts(), Dropped: hist.droppedCount()}
+		}
+		if tracked && hinted {
+			mandated += r.now().Sub(sleeping)
+		}
+		if wait > 0 {
+			slept += wait
+			hist.setDelay(wait)
+		}
+		if r.idempotencyGuard != nil && r.idempotencyGuard(ctx, lastErr) {
+			hist.release()
 			return nil
 		}
-		if r.shouldRetry(lastErr) {
-			realDelay := int(float32(delay) * rand.Float32())
-			time.Sleep(time.Duration(realDelay) * time.Millisecond)
+	}
+
+	exceeded := &ErrMaxAttemptExceeded{
+		Err:          lastErr,
+		Attempts:     hist.attempts(),
+		Dropped:      hist.droppedCount(),
+		AttemptsMade: maxAttempt,
+		Elapsed:      r.now().Sub(begin),
+		Slept:        slept,
+	}
+	if r.observer != nil {
+		r.observer.Observe(ctx, Event{Kind: Exhausted, Attempt: maxAttempt, Err: lastErr})
+	}
+	if r.logger != nil {
+		r.logger.Warn(ctx, "retry attempts exhausted", "attempts", maxAttempt, "error", lastErr, "elapsed", exceeded.Elapsed)
+	}
+	if r.compensate != nil {
+		r.compensate(ctx, exceeded.Attempts)
+	}
+	return exceeded
+}
+
+// elapsedLeft returns what is left of the limit of WithMaxElapsedTime, the mandated waits excluded.
+func (r *Retry) elapsedLeft(begin time.Time, mandated time.Duration) time.Duration {
+	return r.maxElapsed - (r.now().Sub(begin) - mandated)
+}
+
+// MaxAttempts returns the max attempts of r, or 0 if it retries until an attempt succeeds,
+// see WithUnlimitedAttempts.
+func (r Retry) MaxAttempts() int {
+	if r.unlimited {
+		return 0
+	}
+	return r.maxAttempt
+}
+
+// IsRetryable reports whether r retries err: err is marked with MarkRetryable, or shouldRetry
+// accepts it and it is not marked Unrecoverable.
+func (r Retry) IsRetryable(err error) bool {
+	return r.retries(err)
+}
+
+// Delay returns the delay before the n-th retry, i.e. initDelay doubled n-1 times up to maxDelay,
+// randomized by the Jitter set. With DecorrelatedJitter, which depends on the previous delay
+// rather than n, Delay assumes the previous delay was the nominal one.
+func (r Retry) Delay(n int) time.Duration {
+	return r.delay(n, r.nominalDelay(n-1))
+}
+
+// delay returns the delay before the n-th retry, prev being the delay before the previous one.
+func (r Retry) delay(n int, prev time.Duration) time.Duration {
+	switch r.jitter {
+	case NoJitter:
+		return r.nominalDelay(n)
+	case EqualJitter:
+		nominal := r.nominalDelay(n)
+		return nominal/2 + time.Duration(float64(nominal-nominal/2)*r.rand.Float64())
+	case DecorrelatedJitter:
+		if prev < r.initDelay {
+			prev = r.initDelay
+		}
+		upper := r.maxDelay
+		if prev <= r.maxDelay/3 {
+			upper = prev * 3
+		}
+		return r.initDelay + time.Duration(float64(upper-r.initDelay)*r.rand.Float64())
+	default:
+		return time.Duration(float64(r.nominalDelay(n)) * r.rand.Float64())
+	}
+}
+
+// nominalDelay returns the delay of the Backoff set, or initDelay doubled n-1 times up to maxDelay.
+func (r Retry) nominalDelay(n int) time.Duration {
+	if r.backoff != nil {
+		return r.backoff(n)
+	}
+	delay := r.initDelay
+	for i := 1; i < n && delay < r.maxDelay && delay > 0; i++ {
+		if delay > r.maxDelay/2 {
+			// doubling would exceed maxDelay, or even overflow.
+			delay = r.maxDelay
+		} else {
 			delay = delay * 2
-			if delay > r.maxDelay {
-				delay = r.maxDelay
+		}
+	}
+	return delay
+}
+
+// attemptContext returns the context to run the attempt-th attempt with,
+// bounded by the attempt timeout or the deadline slice if any.
+func (r *Retry) attemptContext(ctx context.Context, attempt int, maxAttempt int) (context.Context, context.CancelFunc) {
+	timeout := r.attemptTimeout
+	if deadline, ok := ctx.Deadline(); ok && !r.unlimited {
+		if attempt == maxAttempt && r.fullDeadlineLast {
+			return ctx, func() {}
+		}
+		if r.sliceDeadline {
+			slice := time.Until(deadline) / time.Duration(maxAttempt-attempt+1)
+			if timeout <= 0 || slice < timeout {
+				timeout = slice
 			}
-			continue
 		}
-		return lastErr
 	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func (r *Retry) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	if r.clock != nil {
+		t := r.clock.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C():
+			return nil
+		}
+	}
+	if r.wheel != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.wheel.After(d):
+			return nil
+		}
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// newTimer returns a timer of the clock of r.
+func (r *Retry) newTimer(d time.Duration) Timer {
+	if r.clock != nil {
+		return r.clock.NewTimer(d)
+	}
+	return SystemClock.NewTimer(d)
+}
 
-	return &ErrMaxAttemptExceeded{
-		Err: lastErr,
+// now returns the current time of the Clock set, if any.
+func (r *Retry) now() time.Time {
+	if r.clock != nil {
+		return r.clock.Now()
 	}
+	return time.Now()
 }
 
+// RetryFunc1 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p) }).
 func RetryFunc1[P any](r Retry, f func(P) error, p P) error {
-	return r.Do(
-		func() error {
-			return f(p)
-		},
-	)
+	return r.Do(func() error {
+		return f(p)
+	})
 }
 
+// RetryFunc2 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2) }).
 func RetryFunc2[P1, P2 any](r Retry, f func(P1, P2) error, p1 P1, p2 P2) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2)
+	})
 }
 
+// RetryFunc3 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2, p3) }).
 func RetryFunc3[P1, P2, P3 any](r Retry, f func(P1, P2, P3) error, p1 P1, p2 P2, p3 P3) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2, p3)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2, p3)
+	})
 }
 
+// RetryFunc4 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2, p3, p4) }).
 func RetryFunc4[P1, P2, P3, P4 any](r Retry, f func(P1, P2, P3, P4) error, p1 P1, p2 P2, p3 P3, p4 P4) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2, p3, p4)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2, p3, p4)
+	})
 }
 
+// RetryFunc5 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2, p3, p4, p5) }).
 func RetryFunc5[P1, P2, P3, P4, P5 any](r Retry, f func(P1, P2, P3, P4, P5) error, p1 P1, p2 P2, p3 P3, p4 P4, p5 P5) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2, p3, p4, p5)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2, p3, p4, p5)
+	})
 }
 
+// RetryFunc6 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2, p3, p4, p5, p6) }).
 func RetryFunc6[P1, P2, P3, P4, P5, P6 any](r Retry, f func(P1, P2, P3, P4, P5, P6) error, p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2, p3, p4, p5, p6)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2, p3, p4, p5, p6)
+	})
 }
 
+// RetryFunc7 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2, p3, p4, p5, p6, p7) }).
 func RetryFunc7[P1, P2, P3, P4, P5, P6, P7 any](r Retry, f func(P1, P2, P3, P4, P5, P6, P7) error, p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6, p7 P7) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2, p3, p4, p5, p6, p7)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2, p3, p4, p5, p6, p7)
+	})
 }
 
+// RetryFunc8 calls f with the parameters, retried with r.
+//
+// Deprecated: Use Do with a closure calling f, e.g. r.Do(func() error { return f(p1, p2, p3, p4, p5, p6, p7, p8) }).
 func RetryFunc8[P1, P2, P3, P4, P5, P6, P7, P8 any](r Retry, f func(P1, P2, P3, P4, P5, P6, P7, P8) error, p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6, p7 P7, p8 P8) error {
-	return r.Do(
-		func() error {
-			return f(p1, p2, p3, p4, p5, p6, p7, p8)
-		},
-	)
+	return r.Do(func() error {
+		return f(p1, p2, p3, p4, p5, p6, p7, p8)
+	})
 }
 
+// Retry2 is like DoValue, without a context.
 func Retry2[R any](r Retry, f func() (R, error)) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f()
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f()
 	})
-	return result, err
 }
 
+// Retry2Func1 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func1[R, P1 any](r Retry, f func(P1) (R, error), p1 P1) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1)
 	})
-	return result, err
 }
 
+// Retry2Func2 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func2[R, P1, P2 any](r Retry, f func(P1, P2) (R, error), p1 P1, p2 P2) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2)
 	})
-	return result, err
 }
 
+// Retry2Func3 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func3[R, P1, P2, P3 any](r Retry, f func(P1, P2, P3) (R, error), p1 P1, p2 P2, p3 P3) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2, p3)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2, p3)
 	})
-	return result, err
 }
 
+// Retry2Func4 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func4[R, P1, P2, P3, P4 any](r Retry, f func(P1, P2, P3, P4) (R, error), p1 P1, p2 P2, p3 P3, p4 P4) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2, p3, p4)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2, p3, p4)
 	})
-	return result, err
 }
 
+// Retry2Func5 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func5[R, P1, P2, P3, P4, P5 any](r Retry, f func(P1, P2, P3, P4, P5) (R, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2, p3, p4, p5)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2, p3, p4, p5)
 	})
-	return result, err
 }
 
+// Retry2Func6 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func6[R, P1, P2, P3, P4, P5, P6 any](r Retry, f func(P1, P2, P3, P4, P5, P6) (R, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2, p3, p4, p5, p6)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2, p3, p4, p5, p6)
 	})
-	return result, err
 }
 
+// Retry2Func7 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func7[R, P1, P2, P3, P4, P5, P6, P7 any](r Retry, f func(P1, P2, P3, P4, P5, P6, P7) (R, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6, p7 P7) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2, p3, p4, p5, p6, p7)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2, p3, p4, p5, p6, p7)
 	})
-	return result, err
 }
 
+// Retry2Func8 calls f with the parameters, retried with r, and returns its value.
+//
+// Deprecated: Use DoValue with a closure calling f.
 func Retry2Func8[R, P1, P2, P3, P4, P5, P6, P7, P8 any](r Retry, f func(P1, P2, P3, P4, P5, P6, P7, P8) (R, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6, p7 P7, p8 P8) (R, error) {
-	var result R
-	err := r.Do(func() error {
-		var e error
-		result, e = f(p1, p2, p3, p4, p5, p6, p7, p8)
-		return e
+	return DoValue(context.Background(), r, func(context.Context) (R, error) {
+		return f(p1, p2, p3, p4, p5, p6, p7, p8)
 	})
-	return result, err
 }
 
+// Retry3 is like DoValues2, without a context.
 func Retry3[R1, R2 any](r Retry, f func() (R1, R2, error)) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f()
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f()
 	})
-	return result1, result2, err
 }
 
+// Retry3Func1 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func1[R1, R2, P1 any](r Retry, f func(P1) (R1, R2, error), p1 P1) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func2 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func2[R1, R2, P1, P2 any](r Retry, f func(P1, P2) (R1, R2, error), p1 P1, p2 P2) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func3 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func3[R1, R2, P1, P2, P3 any](r Retry, f func(P1, P2, P3) (R1, R2, error), p1 P1, p2 P2, p3 P3) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2, p3)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2, p3)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func4 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func4[R1, R2, P1, P2, P3, P4 any](r Retry, f func(P1, P2, P3, P4) (R1, R2, error), p1 P1, p2 P2, p3 P3, p4 P4) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2, p3, p4)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2, p3, p4)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func5 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func5[R1, R2, P1, P2, P3, P4, P5 any](r Retry, f func(P1, P2, P3, P4, P5) (R1, R2, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2, p3, p4, p5)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2, p3, p4, p5)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func6 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func6[R1, R2, P1, P2, P3, P4, P5, P6 any](r Retry, f func(P1, P2, P3, P4, P5, P6) (R1, R2, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2, p3, p4, p5, p6)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2, p3, p4, p5, p6)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func7 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func7[R1, R2, P1, P2, P3, P4, P5, P6, P7 any](r Retry, f func(P1, P2, P3, P4, P5, P6, P7) (R1, R2, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6, p7 P7) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2, p3, p4, p5, p6, p7)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2, p3, p4, p5, p6, p7)
 	})
-	return result1, result2, err
 }
 
+// Retry3Func8 calls f with the parameters, retried with r, and returns its values.
+//
+// Deprecated: Use DoValues2 with a closure calling f.
 func Retry3Func8[R1, R2, P1, P2, P3, P4, P5, P6, P7, P8 any](r Retry, f func(P1, P2, P3, P4, P5, P6, P7, P8) (R1, R2, error), p1 P1, p2 P2, p3 P3, p4 P4, p5 P5, p6 P6, p7 P7, p8 P8) (R1, R2, error) {
-	var result1 R1
-	var result2 R2
-	err := r.Do(func() error {
-		var e error
-		result1, result2, e = f(p1, p2, p3, p4, p5, p6, p7, p8)
-		return e
+	return DoValues2(context.Background(), r, func(context.Context) (R1, R2, error) {
+		return f(p1, p2, p3, p4, p5, p6, p7, p8)
+	})
+}
+
+// Retry4 is like DoValues3, without a context.
+func Retry4[R1, R2, R3 any](r Retry, f func() (R1, R2, R3, error)) (R1, R2, R3, error) {
+	return DoValues3(context.Background(), r, func(context.Context) (R1, R2, R3, error) {
+		return f()
 	})
-	return result1, result2, err
 }
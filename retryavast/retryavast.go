@@ -0,0 +1,134 @@
+// Package retryavast mirrors the API of github.com/avast/retry-go on top of retry, so the code using
+// it migrates by changing its import path, and may then move on to retry.Retry at its own pace.
+// It depends on neither module version of avast/retry-go.
+package retryavast
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// The defaults of avast/retry-go.
+const (
+	DefaultAttempts = 10
+	DefaultDelay    = 100 * time.Millisecond
+)
+
+// RetryIfFunc decides whether an error is retried, see RetryIf.
+type RetryIfFunc func(error) bool
+
+// OnRetryFunc is called after a failed attempt, see OnRetry.
+type OnRetryFunc func(n uint, err error)
+
+// Option configures Do, like the options of the same name of avast/retry-go.
+type Option func(*config)
+
+type config struct {
+	attempts uint
+	delay    time.Duration
+	maxDelay time.Duration
+	onRetry  OnRetryFunc
+	retryIf  RetryIfFunc
+	ctx      context.Context
+}
+
+// Attempts sets the number of attempts, 10 by default. 0 retries until an attempt succeeds, an
+// error is not retried, or the context is done.
+func Attempts(attempts uint) Option {
+	return func(c *config) { c.attempts = attempts }
+}
+
+// Delay sets the delay before the first retry, 100ms by default. It doubles on every retry.
+func Delay(delay time.Duration) Option {
+	return func(c *config) { c.delay = delay }
+}
+
+// MaxDelay caps the delays, which are not capped by default.
+func MaxDelay(maxDelay time.Duration) Option {
+	return func(c *config) { c.maxDelay = maxDelay }
+}
+
+// OnRetry sets a function called before every retry with the 0-based number of the attempt which
+// failed and its error. Unlike in avast/retry-go, it is not called after the last attempt.
+func OnRetry(onRetry OnRetryFunc) Option {
+	return func(c *config) { c.onRetry = onRetry }
+}
+
+// RetryIf sets the errors retried, every error but those marked Unrecoverable by default.
+// The errors marked Unrecoverable are never retried.
+func RetryIf(retryIf RetryIfFunc) Option {
+	return func(c *config) { c.retryIf = retryIf }
+}
+
+// Context sets the context Do gives up once done, context.Background() by default.
+func Context(ctx context.Context) Option {
+	return func(c *config) { c.ctx = ctx }
+}
+
+// Unrecoverable marks err as not to be retried, whatever RetryIf says. It is retry.Unrecoverable.
+func Unrecoverable(err error) error {
+	return retry.Unrecoverable(err)
+}
+
+// IsRecoverable reports whether err is not marked Unrecoverable.
+func IsRecoverable(err error) bool {
+	return !retry.IsUnrecoverable(err)
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		attempts: DefaultAttempts,
+		delay:    DefaultDelay,
+		maxDelay: math.MaxInt64,
+		retryIf:  IsRecoverable,
+		ctx:      context.Background(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// New translates opts into a retry.Retry. The Context option, which is not part of a policy, is ignored.
+func New(opts ...Option) retry.Retry {
+	return newConfig(opts).policy()
+}
+
+func (c *config) policy() retry.Retry {
+	attempts := int(c.attempts)
+	if c.attempts > math.MaxInt32 {
+		attempts = math.MaxInt32
+	}
+	r := retry.NewWithDurations(c.retryIf, attempts, c.delay, c.maxDelay)
+	if c.attempts == 0 {
+		r = r.WithUnlimitedAttempts()
+	}
+	if c.onRetry != nil {
+		onRetry := c.onRetry
+		r = r.WithOnRetry(func(attempt int, err error, _ time.Duration) {
+			onRetry(uint(attempt-1), err)
+		})
+	}
+	return r
+}
+
+// Do calls f until it succeeds, configured by opts. It returns the errors of retry.Retry, e.g.
+// retry.ErrMaxAttemptExceeded once the attempts are exhausted, rather than the list of the errors
+// of every attempt avast/retry-go returns; the error of the last attempt is wrapped in them.
+func Do(f func() error, opts ...Option) error {
+	c := newConfig(opts)
+	return c.policy().DoContext(c.ctx, func(context.Context) error {
+		return f()
+	})
+}
+
+// DoWithData is like Do, for a function returning a value along with its error.
+func DoWithData[T any](f func() (T, error), opts ...Option) (T, error) {
+	c := newConfig(opts)
+	return retry.DoValue(c.ctx, c.policy(), func(context.Context) (T, error) {
+		return f()
+	})
+}
@@ -0,0 +1,90 @@
+// Package retryaws classifies the errors of the AWS SDKs for Go, v1 and v2, for retry.
+// It depends on neither SDK: it recognizes their errors by the methods they implement.
+package retryaws
+
+import (
+	"errors"
+	"io"
+	"syscall"
+)
+
+// throttleCodes are the error codes AWS services throttle requests with.
+var throttleCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"RequestThrottledException":              true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+	"TransactionInProgressException":         true,
+	"RequestLimitExceeded":                   true,
+	"BandwidthLimitExceeded":                 true,
+	"LimitExceededException":                 true,
+	"RequestThrottled":                       true,
+	"SlowDown":                               true,
+	"PriorRequestNotComplete":                true,
+	"EC2ThrottledException":                  true,
+}
+
+// transientCodes are the error codes of AWS failures other than throttling which a retry may get past.
+var transientCodes = map[string]bool{
+	"RequestTimeout":          true,
+	"RequestTimeoutException": true,
+	"InternalError":           true,
+	"InternalFailure":         true,
+	"ServiceUnavailable":      true,
+}
+
+// IsThrottle reports whether err is an AWS throttling error, by its error code.
+func IsThrottle(err error) bool {
+	code, ok := errorCode(err)
+	return ok && throttleCodes[code]
+}
+
+// ShouldRetry is a shouldRetry predicate retrying the throttling and transient errors of AWS:
+// the throttling error codes, timeouts and internal errors of the services, 5xx responses,
+// and connections reset or cut short.
+func ShouldRetry(err error) bool {
+	if code, ok := errorCode(err); ok && (throttleCodes[code] || transientCodes[code]) {
+		return true
+	}
+	if status, ok := statusCode(err); ok && status >= 500 && status != 501 {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// errorCode returns the error code of err: smithy.APIError of v2 has ErrorCode, awserr.Error of v1 has Code.
+func errorCode(err error) (string, bool) {
+	var v2 interface{ ErrorCode() string }
+	if errors.As(err, &v2) {
+		return v2.ErrorCode(), true
+	}
+	var v1 interface {
+		Code() string
+		OrigErr() error
+	}
+	if errors.As(err, &v1) {
+		return v1.Code(), true
+	}
+	return "", false
+}
+
+// statusCode returns the HTTP status code of the response err came with:
+// smithyhttp.ResponseError of v2 has HTTPStatusCode, awserr.RequestFailure of v1 has StatusCode.
+func statusCode(err error) (int, bool) {
+	var v2 interface{ HTTPStatusCode() int }
+	if errors.As(err, &v2) {
+		return v2.HTTPStatusCode(), true
+	}
+	var v1 interface {
+		StatusCode() int
+		RequestID() string
+	}
+	if errors.As(err, &v1) {
+		return v1.StatusCode(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,12 @@
+module github.com/bluexlab/retry-go/retryawsv2
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.24.1
+	github.com/bluexlab/retry-go v0.1.0
+)
+
+require github.com/aws/smithy-go v1.19.0 // indirect
+
+replace github.com/bluexlab/retry-go => ../
@@ -0,0 +1,72 @@
+// Package retryawsv2 configures the clients of the AWS SDK for Go v2 to retry with a retry.Retry,
+// so they share the retry semantics used elsewhere in a service.
+package retryawsv2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/bluexlab/retry-go"
+)
+
+// Retryer is an aws.RetryerV2 backed by a retry.Retry, e.g. for the Retryer field of aws.Config or
+// of the options of a client. The SDK runs the retry loop itself, so only the predicate, the attempt
+// limit, the delays and the FailureRateGate of the policy apply, as well as the marks and delay hints
+// on the errors. The retry tokens are free: the RetryBudget, RetryQuota and Limiter of the policy
+// do not apply, and neither do the client-side rate limits of the SDK.
+//
+// The classifiers of retryaws recognize the errors of the SDK, e.g.
+//
+//	retryawsv2.NewRetryer(retry.NewWithDurations(retryaws.ShouldRetry, 5, 50*time.Millisecond, 20*time.Second))
+type Retryer struct {
+	policy retry.Retry
+}
+
+var _ aws.RetryerV2 = (*Retryer)(nil)
+
+// NewRetryer creates a Retryer retrying with policy.
+func NewRetryer(policy retry.Retry) *Retryer {
+	return &Retryer{policy: policy}
+}
+
+// IsErrorRetryable reports whether the policy retries err.
+func (r *Retryer) IsErrorRetryable(err error) bool {
+	return r.policy.IsRetryable(err)
+}
+
+// MaxAttempts returns the max attempts of the policy, 0 if they are unlimited.
+func (r *Retryer) MaxAttempts() int {
+	return r.policy.MaxAttempts()
+}
+
+// RetryDelay returns the delay before retrying after the attempt-th attempt failed with err: the one
+// hinted by err if any, or the delay of the policy. It returns an error if the policy advises
+// against retrying after all, e.g. while its FailureRateGate suspends the retries.
+func (r *Retryer) RetryDelay(attempt int, err error) (time.Duration, error) {
+	advice := r.policy.Advise(attempt, err)
+	if !advice.Retry {
+		return 0, fmt.Errorf("retry not advised: %s", advice.Reason)
+	}
+	return advice.Delay, nil
+}
+
+// GetRetryToken returns a free retry token.
+func (r *Retryer) GetRetryToken(context.Context, error) (func(error) error, error) {
+	return releaseToken, nil
+}
+
+// GetInitialToken returns a free token.
+func (r *Retryer) GetInitialToken() func(error) error {
+	return releaseToken
+}
+
+// GetAttemptToken returns a free attempt token.
+func (r *Retryer) GetAttemptToken(context.Context) (func(error) error, error) {
+	return releaseToken, nil
+}
+
+func releaseToken(error) error {
+	return nil
+}
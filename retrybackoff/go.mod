@@ -0,0 +1,10 @@
+module github.com/bluexlab/retry-go/retrybackoff
+
+go 1.21
+
+require (
+	github.com/bluexlab/retry-go v0.1.0
+	github.com/cenkalti/backoff/v4 v4.3.0
+)
+
+replace github.com/bluexlab/retry-go => ../
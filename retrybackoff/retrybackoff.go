@@ -0,0 +1,68 @@
+// Package retrybackoff adapts the backoff policies of github.com/cenkalti/backoff to retry and back,
+// so code using either library can migrate to the other incrementally.
+package retrybackoff
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/cenkalti/backoff/v4"
+)
+
+// FromBackOff returns a retry.Backoff taking its delays from the BackOff policies newBackOff
+// creates, for retry.Retry.WithBackoff. As a BackOff is stateful while a Backoff is not, the n-th
+// delay is the n-th one of a fresh BackOff, so the Backoff is safe for concurrent use.
+//
+// The retries stay bounded by the policy alone: the delays after backoff.Stop repeat the last one,
+// and the MaxElapsedTime of a backoff.ExponentialBackOff never passes; use the max attempts and
+// WithMaxElapsedTime of the policy instead. The delays are still randomized by the Jitter of the
+// policy, so set retry.NoJitter to keep the randomization of the BackOff alone.
+func FromBackOff(newBackOff func() backoff.BackOff) retry.Backoff {
+	return func(n int) time.Duration {
+		b := newBackOff()
+		var delay time.Duration
+		for i := 0; i < n; i++ {
+			next := b.NextBackOff()
+			if next == backoff.Stop {
+				break
+			}
+			delay = next
+		}
+		return delay
+	}
+}
+
+// errNominal is the error the delays of a policy are computed for, which it always retries.
+var errNominal = retry.MarkRetryable(errors.New("nominal"))
+
+// BackOff is a backoff.BackOff taking its delays from a retry.Retry, and stopping when it gives up.
+// Like the BackOff policies of github.com/cenkalti/backoff, it is not safe for concurrent use.
+type BackOff struct {
+	policy  retry.Retry
+	attempt int
+}
+
+var _ backoff.BackOff = (*BackOff)(nil)
+
+// ToBackOff returns a BackOff with the delays and the max attempts of policy, as well as its
+// FailureRateGate if any, e.g. for backoff.Retry. The predicate of policy does not apply, as the
+// BackOff never sees the errors; wrap them in backoff.Permanent instead.
+func ToBackOff(policy retry.Retry) *BackOff {
+	return &BackOff{policy: policy}
+}
+
+// NextBackOff returns the delay before the next retry, or backoff.Stop once the policy gives up.
+func (b *BackOff) NextBackOff() time.Duration {
+	b.attempt++
+	advice := b.policy.Advise(b.attempt, errNominal)
+	if !advice.Retry {
+		return backoff.Stop
+	}
+	return advice.Delay
+}
+
+// Reset starts over with the first retry.
+func (b *BackOff) Reset() {
+	b.attempt = 0
+}
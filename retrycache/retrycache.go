@@ -0,0 +1,63 @@
+// Package retrycache falls back on the last value loaded when retrying a load fails.
+package retrycache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// Loader loads values by key, retrying the load according to a policy, and remembers the last value
+// loaded for every key. When the policy gives up on a load, the last value loaded for the key returns
+// instead, so e.g. a service keeps running on its last known configuration or feature flags while the
+// source is down. The failure still reaches the logger and observer of the policy, if any.
+// It is safe for concurrent use; concurrent loads of a key are not merged, see retry.Coalescer.
+type Loader[K comparable, V any] struct {
+	policy retry.Retry
+	load   func(ctx context.Context, key K) (V, error)
+
+	mu     sync.Mutex
+	values map[K]entry[V]
+}
+
+type entry[V any] struct {
+	value  V
+	loaded time.Time
+}
+
+// NewLoader creates a Loader loading the values with load, retried with policy.
+func NewLoader[K comparable, V any](policy retry.Retry, load func(ctx context.Context, key K) (V, error)) *Loader[K, V] {
+	return &Loader[K, V]{policy: policy, load: load, values: map[K]entry[V]{}}
+}
+
+// Load loads the value of key and returns it with an age of 0. If the load fails, it returns the last
+// value loaded for key instead, with the time since it was loaded, as long as there is one and ctx is
+// not done; otherwise the error of the load returns.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, time.Duration, error) {
+	var v V
+	err := l.policy.DoContext(ctx, func(ctx context.Context) error {
+		var err error
+		v, err = l.load(ctx, key)
+		return err
+	})
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil {
+		l.values[key] = entry[V]{value: v, loaded: time.Now()}
+		return v, 0, nil
+	}
+	if e, ok := l.values[key]; ok && ctx.Err() == nil {
+		return e.value, time.Since(e.loaded), nil
+	}
+	var zero V
+	return zero, 0, err
+}
+
+// Forget drops the last value loaded for key, so a failing load of key returns its error.
+func (l *Loader[K, V]) Forget(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.values, key)
+}
@@ -0,0 +1,23 @@
+package retrygrpc
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryOnCodes returns a shouldRetry predicate retrying the errors carrying a gRPC status with one
+// of the given codes, found by status.FromError, so the status may be wrapped in other errors.
+func RetryOnCodes(retryable ...codes.Code) func(error) bool {
+	return func(err error) bool {
+		s, ok := status.FromError(err)
+		if !ok {
+			return false
+		}
+		for _, code := range retryable {
+			if s.Code() == code {
+				return true
+			}
+		}
+		return false
+	}
+}
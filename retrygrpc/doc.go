@@ -0,0 +1,2 @@
+// Package retrygrpc adapts retry to gRPC clients.
+package retrygrpc
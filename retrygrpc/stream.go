@@ -0,0 +1,124 @@
+package retrygrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/bluexlab/retry-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// StreamClientInterceptor returns a client interceptor retrying the establishment of streams
+// according to policy. With reestablish, a server-streaming call whose stream breaks before
+// the first response message is received is transparently opened again and sent the request
+// anew, as nothing was observed by the caller yet. Other kinds of streams are never reopened,
+// since the messages sent by the client cannot be replayed safely.
+//
+// The streams are opened with the context of the call, so the policy's WithAttemptTimeout
+// does not apply. Once the policy gives up, the error of the last attempt returns as is,
+// or the status of the context if it is done, so callers see the usual gRPC errors.
+func StreamClientInterceptor(policy retry.Retry, reestablish bool) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		err := policy.DoContext(ctx, func(context.Context) error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		})
+		if err != nil {
+			return nil, callError(ctx, err)
+		}
+		if !reestablish || desc.ClientStreams || !desc.ServerStreams {
+			return stream, nil
+		}
+		return &retryStream{
+			ClientStream: stream,
+			policy:       policy,
+			open: func() (grpc.ClientStream, error) {
+				return streamer(ctx, desc, cc, method, opts...)
+			},
+			ctx: ctx,
+		}, nil
+	}
+}
+
+// retryStream reopens a server stream which breaks before its first response message.
+type retryStream struct {
+	grpc.ClientStream
+	policy retry.Retry
+	open   func() (grpc.ClientStream, error)
+	ctx    context.Context
+
+	request  any // the single request message of the server stream
+	sent     bool
+	closed   bool
+	received bool
+}
+
+func (s *retryStream) SendMsg(m any) error {
+	if !s.received {
+		s.request = m
+		s.sent = true
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *retryStream) CloseSend() error {
+	s.closed = true
+	return s.ClientStream.CloseSend()
+}
+
+func (s *retryStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil || s.received || err == io.EOF {
+		s.received = true
+		return err
+	}
+	broken := true
+	err = s.policy.DoContext(s.ctx, func(context.Context) error {
+		if broken {
+			// the stream at hand failing counts as the first attempt.
+			broken = false
+			return err
+		}
+		stream, err := s.open()
+		if err != nil {
+			return err
+		}
+		s.ClientStream = stream
+		if s.sent {
+			if err := stream.SendMsg(s.request); err != nil {
+				return err
+			}
+		}
+		if s.closed {
+			if err := stream.CloseSend(); err != nil {
+				return err
+			}
+		}
+		return stream.RecvMsg(m)
+	})
+	s.received = true
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return callError(s.ctx, err)
+}
+
+// callError returns the error a gRPC call gets for err, the error a policy gave up with.
+func callError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	var exceeded *retry.ErrMaxAttemptExceeded
+	if errors.As(err, &exceeded) {
+		return exceeded.Err
+	}
+	var aborted *retry.ErrAborted
+	if errors.As(err, &aborted) && aborted.Err != nil {
+		return aborted.Err
+	}
+	return err
+}
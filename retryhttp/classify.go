@@ -0,0 +1,40 @@
+package retryhttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+)
+
+// RetryOnStatus returns a shouldRetry predicate retrying the StatusError of the given status codes.
+func RetryOnStatus(codes ...int) func(error) bool {
+	return func(err error) bool {
+		var status *StatusError
+		if !errors.As(err, &status) {
+			return false
+		}
+		for _, code := range codes {
+			if status.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var retryDefaultStatus = RetryOnStatus(
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+)
+
+// DefaultShouldRetry is a shouldRetry predicate retrying the usual transient HTTP failures:
+// the StatusError of 429, 502, 503 and 504 responses, and connections reset or cut short.
+func DefaultShouldRetry(err error) bool {
+	return retryDefaultStatus(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
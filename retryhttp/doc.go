@@ -0,0 +1,2 @@
+// Package retryhttp adapts retry to HTTP clients and servers.
+package retryhttp
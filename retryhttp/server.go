@@ -0,0 +1,103 @@
+package retryhttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// Backpressure is an HTTP middleware telling clients when to come back while the server is overloaded.
+// Requests are shed with 503 Service Unavailable when the shed hook says so, and every 429 or 503
+// response, shed or written by the handler, gets a Retry-After header computed by the backoff of
+// a Retry, so clients and servers share one model of backpressure. The delay grows with the number
+// of consecutive overloaded responses and resets once a request is served normally.
+//
+// The handlers keep flushing and hijacking the response as usual: the writer they are given is an
+// http.Flusher and an http.Hijacker, whose Hijack fails with http.ErrNotSupported if the original
+// writer cannot be hijacked.
+type Backpressure struct {
+	policy retry.Retry
+	shed   func(*http.Request) bool
+	streak atomic.Int64
+}
+
+// NewBackpressure creates a Backpressure advising delays by policy.
+// shed decides whether to reject a request up front; it may be nil.
+func NewBackpressure(policy retry.Retry, shed func(*http.Request) bool) *Backpressure {
+	return &Backpressure{
+		policy: policy,
+		shed:   shed,
+	}
+}
+
+// Handler wraps next with the middleware.
+func (b *Backpressure) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if b.shed != nil && b.shed(req) {
+			w.Header().Set("Retry-After", b.retryAfter())
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(&backpressureWriter{ResponseWriter: w, b: b}, req)
+	})
+}
+
+// retryAfter counts an overloaded response and returns the Retry-After value for it in seconds.
+func (b *Backpressure) retryAfter() string {
+	n := b.streak.Add(1)
+	seconds := (b.policy.Delay(int(n)) + time.Second - 1) / time.Second
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(int64(seconds), 10)
+}
+
+type backpressureWriter struct {
+	http.ResponseWriter
+	b           *Backpressure
+	wroteHeader bool
+}
+
+func (w *backpressureWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable {
+			if w.Header().Get("Retry-After") == "" {
+				w.Header().Set("Retry-After", w.b.retryAfter())
+			}
+		} else {
+			w.b.streak.Store(0)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *backpressureWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the original http.ResponseWriter for http.ResponseController.
+func (w *backpressureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush implements http.Flusher, writing the header first if needed.
+func (w *backpressureWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker if the original http.ResponseWriter does.
+func (w *backpressureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
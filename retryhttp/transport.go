@@ -0,0 +1,170 @@
+package retryhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/idempotency"
+)
+
+// maxDrain is how much of the body of a response given up on is read, so the connection can be reused.
+const maxDrain = 4 << 10
+
+// StatusError is the error an attempt of Transport fails with when the response has an error
+// status, for the shouldRetry of the policy to decide on.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+
+	retryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return "retryhttp: unexpected status " + e.Status
+}
+
+// RetryAfter returns the delay the server asked for in the Retry-After header of a 429 or 503
+// response, as limited by Transport.WithMaxRetryAfter, or 0 if there is none. It makes StatusError
+// a retry.DelayHinter, so the policy waits that long instead of its own delay.
+func (e *StatusError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Transport is an http.RoundTripper retrying idempotent requests according to a Retry policy.
+// A request is idempotent if its method is, or if it carries an idempotency key in the
+// idempotency.Header header. A request with a body is retried only if it can be rewound with
+// GetBody, which http.NewRequest sets for the common body types.
+//
+// An attempt fails with the error of the base RoundTripper, or with a StatusError for a response
+// with an error status, 400 or above; DefaultShouldRetry and RetryOnStatus make shouldRetry
+// predicates telling which of them to retry. The body of a response given up on is drained and closed
+// before the next attempt. Once the policy stops retrying a StatusError, the last response returns
+// as is, so the caller sees what the server answered. The Retry-After header of 429 and 503
+// responses replaces the delay of the policy, see StatusError.RetryAfter.
+//
+// The requests are sent with their own context, which the policy stops retrying on as well.
+// The policy's WithAttemptTimeout does not apply, since it would cut the reading of the body short;
+// use http.Client.Timeout or the request context instead.
+type Transport struct {
+	policy        retry.Retry
+	base          http.RoundTripper
+	maxRetryAfter time.Duration
+}
+
+// DefaultMaxRetryAfter is the longest Retry-After delay a Transport honors unless WithMaxRetryAfter
+// says otherwise, so that a broken or hostile server cannot park a request for days.
+const DefaultMaxRetryAfter = 5 * time.Minute
+
+// NewTransport creates a Transport sending the requests with base, or http.DefaultTransport if base is nil.
+func NewTransport(policy retry.Retry, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{policy: policy, base: base, maxRetryAfter: DefaultMaxRetryAfter}
+}
+
+// WithMaxRetryAfter returns a copy of t honoring the Retry-After header up to d: a longer delay
+// asked by the server is shortened to d. It is DefaultMaxRetryAfter by default; a d of 0 or less
+// honors the header whatever it says.
+func (t *Transport) WithMaxRetryAfter(d time.Duration) *Transport {
+	c := *t
+	c.maxRetryAfter = d
+	return &c
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !retryable(req) {
+		return t.base.RoundTrip(req)
+	}
+	var last *http.Response
+	attempt := 0
+	err := t.policy.DoContext(req.Context(), func(context.Context) error {
+		if last != nil {
+			drain(last)
+			last = nil
+		}
+		attempt++
+		r := req
+		if attempt > 1 {
+			r = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := req.GetBody()
+				if err != nil {
+					return retry.Unrecoverable(err)
+				}
+				r.Body = body
+			}
+		}
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return err
+		}
+		last = resp
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Header: resp.Header, retryAfter: t.retryAfter(resp)}
+		}
+		return nil
+	})
+	var status *StatusError
+	if err == nil || (last != nil && errors.As(err, &status) && req.Context().Err() == nil) {
+		return last, nil
+	}
+	if last != nil {
+		drain(last)
+	}
+	return nil, err
+}
+
+// retryable reports whether req may be sent again.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(idempotency.Header) != ""
+}
+
+// retryAfter returns the delay asked for in the Retry-After header of resp, or 0 if there is none.
+// The header is either a number of seconds or an HTTP date.
+func (t *Transport) retryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	var d time.Duration
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds > int64(math.MaxInt64/time.Second) {
+			d = math.MaxInt64
+		} else {
+			d = time.Duration(seconds) * time.Second
+		}
+	} else if date, err := http.ParseTime(value); err == nil {
+		d = time.Until(date)
+	}
+	if d < 0 {
+		return 0
+	}
+	if t.maxRetryAfter > 0 && d > t.maxRetryAfter {
+		return t.maxRetryAfter
+	}
+	return d
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+	_ = resp.Body.Close()
+}
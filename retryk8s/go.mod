@@ -0,0 +1,16 @@
+module github.com/bluexlab/retry-go/retryk8s
+
+go 1.21
+
+require (
+	github.com/bluexlab/retry-go v0.1.0
+	k8s.io/apimachinery v0.29.3
+)
+
+require (
+	github.com/go-logr/logr v1.3.0 // indirect
+	k8s.io/klog/v2 v2.110.1 // indirect
+	k8s.io/utils v0.0.0-20230726121419-3b25d923346b // indirect
+)
+
+replace github.com/bluexlab/retry-go => ../
@@ -0,0 +1,107 @@
+// Package retryk8s converts the wait.Backoff of k8s.io/apimachinery to retry and back, so the
+// operators and controllers built on client-go can standardize on a single retry implementation.
+package retryk8s
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// FromBackoff returns a retry.Retry retrying the errors shouldRetry accepts the way
+// wait.ExponentialBackoff checks a condition with b: at most b.Steps attempts, fewer if the delays
+// reach b.Cap first, separated by b.Duration multiplied by b.Factor after every retry and randomized
+// by b.Jitter. The policy is invalid if b.Steps <= 0, as wait.ExponentialBackoff would not check
+// its condition at all.
+func FromBackoff(b wait.Backoff, shouldRetry func(error) bool) retry.Retry {
+	attempts, delays := simulate(b)
+	maxDelay := b.Cap
+	if maxDelay <= 0 && len(delays) > 0 {
+		maxDelay = delays[len(delays)-1]
+	}
+	if maxDelay < b.Duration {
+		maxDelay = b.Duration
+	}
+	jitter := b.Jitter
+	return retry.NewWithDurations(shouldRetry, attempts, b.Duration, maxDelay).
+		WithJitter(retry.NoJitter).
+		WithBackoff(func(n int) time.Duration {
+			var d time.Duration
+			switch {
+			case len(delays) == 0:
+				d = b.Duration
+			case n <= len(delays):
+				d = delays[n-1]
+			default:
+				d = delays[len(delays)-1]
+			}
+			if jitter > 0 {
+				d = wait.Jitter(d, jitter)
+			}
+			return d
+		})
+}
+
+// simulate returns the number of times wait.ExponentialBackoff checks its condition with b, and the
+// nominal delays between the checks. The delays stop once they do not change anymore.
+func simulate(b wait.Backoff) (int, []time.Duration) {
+	b.Jitter = 0
+	attempts := 0
+	var delays []time.Duration
+	for b.Steps > 0 {
+		attempts++
+		if b.Steps == 1 {
+			break
+		}
+		prev := b.Duration
+		d := b.Step()
+		if b.Steps == 0 {
+			// the delay reached the cap, and the condition is not checked after it.
+			break
+		}
+		delays = append(delays, d)
+		if b.Duration == prev {
+			// constant from now on, for the remaining steps.
+			attempts += b.Steps
+			break
+		}
+	}
+	return attempts, delays
+}
+
+// errNotDone is the error of an attempt whose condition is not met yet.
+var errNotDone = retry.MarkRetryable(errors.New("condition not met"))
+
+// ExponentialBackoff checks condition like wait.ExponentialBackoffWithContext, but with the attempts
+// and delays of policy, until it is met. It returns nil once the condition is met, the error of the
+// condition as soon as it returns one, wait.ErrWaitTimeout once policy gives up, or ctx.Err() once
+// ctx is done. Any other error ending the retries of policy, e.g. retry.ErrCircuitOpen, is returned as is.
+func ExponentialBackoff(ctx context.Context, policy retry.Retry, condition wait.ConditionWithContextFunc) error {
+	var condErr error
+	err := policy.DoContext(ctx, func(ctx context.Context) error {
+		done, err := condition(ctx)
+		switch {
+		case err != nil:
+			condErr = err
+			return retry.Unrecoverable(err)
+		case !done:
+			return errNotDone
+		}
+		return nil
+	})
+	var aborted *retry.ErrAborted
+	switch {
+	case err == nil:
+		return nil
+	case condErr != nil && errors.Is(err, condErr):
+		return condErr
+	case errors.As(err, &aborted) && ctx.Err() != nil:
+		return ctx.Err()
+	case errors.Is(err, retry.ErrExhausted):
+		return wait.ErrWaitTimeout
+	}
+	return err
+}
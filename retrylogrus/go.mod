@@ -0,0 +1,12 @@
+module github.com/bluexlab/retry-go/retrylogrus
+
+go 1.21
+
+require (
+	github.com/bluexlab/retry-go v0.1.0
+	github.com/sirupsen/logrus v1.9.3
+)
+
+require golang.org/x/sys v0.21.0 // indirect
+
+replace github.com/bluexlab/retry-go => ../
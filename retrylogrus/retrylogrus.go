@@ -0,0 +1,36 @@
+// Package retrylogrus adapts logrus loggers to retry.
+package retrylogrus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/sirupsen/logrus"
+)
+
+// New returns a retry.Logger logging to l, a *logrus.Logger or *logrus.Entry, with the key-value
+// pairs of the records as fields. The context of a record is attached to the entry.
+func New(l logrus.FieldLogger) retry.Logger {
+	return logger{l}
+}
+
+type logger struct {
+	l logrus.FieldLogger
+}
+
+func (g logger) Debug(ctx context.Context, msg string, keyvals ...any) {
+	g.entry(ctx, keyvals).Debug(msg)
+}
+
+func (g logger) Warn(ctx context.Context, msg string, keyvals ...any) {
+	g.entry(ctx, keyvals).Warn(msg)
+}
+
+func (g logger) entry(ctx context.Context, keyvals []any) *logrus.Entry {
+	fields := make(logrus.Fields, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields[fmt.Sprint(keyvals[i])] = keyvals[i+1]
+	}
+	return g.l.WithFields(fields).WithContext(ctx)
+}
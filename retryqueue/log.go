@@ -0,0 +1,121 @@
+package retryqueue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// The operations of the records of the log.
+const (
+	opAdd   = "add"   // a job was enqueued
+	opRetry = "retry" // an attempt of a job failed, and it is to be retried
+	opDone  = "done"  // a job succeeded
+	opDead  = "dead"  // a job was given up on
+)
+
+// record is a line of the log, in JSON.
+type record struct {
+	Op      string `json:"op"`
+	ID      string `json:"id"`
+	Handler string `json:"handler,omitempty"`
+	Payload []byte `json:"payload,omitempty"`
+	Attempt int    `json:"attempt,omitempty"`
+	Next    int64  `json:"next,omitempty"` // in Unix nanoseconds
+	Error   string `json:"error,omitempty"`
+}
+
+// replay reads the log at path and returns the jobs pending at its end.
+// A torn last line, left by a crash in the middle of a write, is ignored.
+func replay(path string) (map[string]*job, error) {
+	jobs := map[string]*job{}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return jobs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// a line without its newline was not completely written.
+			return jobs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, err
+		}
+		switch rec.Op {
+		case opAdd:
+			jobs[rec.ID] = &job{Job: Job{ID: rec.ID, Handler: rec.Handler, Payload: rec.Payload}}
+		case opRetry:
+			if j, ok := jobs[rec.ID]; ok {
+				j.Attempts = rec.Attempt
+				j.LastError = rec.Error
+				j.next = time.Unix(0, rec.Next)
+			}
+		case opDone, opDead:
+			delete(jobs, rec.ID)
+		}
+	}
+}
+
+// compact rewrites the log at path with the records of jobs alone, and returns it open for appending
+// with the number of records it holds.
+func compact(path string, jobs map[string]*job) (*os.File, int, error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, 0, err
+	}
+	w := bufio.NewWriter(f)
+	n := 0
+	for _, j := range jobs {
+		if err := writeRecord(w, record{Op: opAdd, ID: j.ID, Handler: j.Handler, Payload: j.Payload}); err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		n++
+		if j.Attempts > 0 {
+			if err := writeRecord(w, j.retryRecord()); err != nil {
+				f.Close()
+				return nil, 0, err
+			}
+			n++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, 0, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	return file, n, err
+}
+
+func writeRecord(w io.Writer, rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,294 @@
+// Package retryqueue retries operations across process restarts, by persisting them to disk.
+package retryqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// ErrClosed is returned by the methods of a closed Queue.
+var ErrClosed = errors.New("retryqueue: queue closed")
+
+// Handler performs the operation of a job from its payload.
+type Handler func(ctx context.Context, payload []byte) error
+
+// Job is an operation in a Queue.
+type Job struct {
+	ID        string
+	Handler   string // the name of the Handler performing it
+	Payload   []byte
+	Attempts  int    // the attempts which failed so far
+	LastError string // the error of the last attempt which failed, if any
+}
+
+type job struct {
+	Job
+	next time.Time // when the next attempt is due
+}
+
+func (j *job) retryRecord() record {
+	return record{Op: opRetry, ID: j.ID, Attempt: j.Attempts, Next: j.next.UnixNano(), Error: j.LastError}
+}
+
+// Queue is a durable queue of operations, e.g. webhook deliveries or outbox messages, retried with
+// a policy until they succeed or the policy gives up, across process restarts. The operations are
+// persisted as the name of a Handler and a payload to an append-only log, synced to disk before
+// Enqueue returns and after every attempt, and compacted when the queue is opened and whenever the
+// log doubled since, once past a thousand records. An operation is therefore performed at least
+// once, and may be performed again if the process stops in the middle of an attempt; the handlers
+// must be idempotent.
+//
+// The policy decides with Retry.Advise, so its predicate, attempt limit, delays and failure rate
+// gate apply, as well as the marks and delay hints on the errors. While the gate suspends retries,
+// the jobs are put off by the delay of the policy rather than given up on. A job whose handler is not
+// registered fails with an unrecoverable error. It is safe for concurrent use.
+type Queue struct {
+	policy retry.Retry
+	path   string
+
+	mu        sync.Mutex
+	file      *os.File
+	records   int // in the log
+	compactAt int // the number of records at which the log is compacted
+	jobs      map[string]*job
+	handlers  map[string]Handler
+	wakeup    chan struct{}
+
+	deadLetter func(ctx context.Context, job Job, err error)
+}
+
+// Open opens the queue persisted at path, creating it if needed, retrying the jobs with policy.
+// The jobs pending when the queue was last used resume with their attempts and delays.
+func Open(path string, policy retry.Retry) (*Queue, error) {
+	jobs, err := replay(path)
+	if err != nil {
+		return nil, err
+	}
+	file, n, err := compact(path, jobs)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{
+		policy:    policy,
+		path:      path,
+		file:      file,
+		records:   n,
+		compactAt: compactAt(n),
+		jobs:      jobs,
+		handlers:  map[string]Handler{},
+		wakeup:    make(chan struct{}, 1),
+	}, nil
+}
+
+// minCompact is the number of records below which a Queue does not bother compacting its log.
+const minCompact = 1024
+
+// compactAt returns the number of records at which a log compacted to n records is compacted again.
+func compactAt(n int) int {
+	if 2*n < minCompact {
+		return minCompact
+	}
+	return 2 * n
+}
+
+// Handle registers h as the handler named name. The handlers must be registered before Run.
+func (q *Queue) Handle(name string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[name] = h
+}
+
+// WithDeadLetter makes the queue hand every job it gives up on to f, with the error of its last
+// attempt, e.g. to publish it to a dead-letter topic or raise an alert, rather than just dropping it.
+// f is called by Run before the job leaves the log, so a job is handed to f at least once: if the
+// process stops in between, the job is attempted again after the restart and, failing again, handed
+// to f again. It must be called before Run.
+func (q *Queue) WithDeadLetter(f func(ctx context.Context, job Job, err error)) *Queue {
+	q.deadLetter = f
+	return q
+}
+
+// Enqueue persists a job performed by the handler named handler with payload, due at once,
+// and returns its ID.
+func (q *Queue) Enqueue(handler string, payload []byte) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	j := &job{Job: Job{ID: hex.EncodeToString(b[:]), Handler: handler, Payload: payload}, next: time.Now()}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.append(record{Op: opAdd, ID: j.ID, Handler: handler, Payload: payload}); err != nil {
+		return "", err
+	}
+	q.jobs[j.ID] = j
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+	return j.ID, nil
+}
+
+// Len returns the number of jobs pending.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// Run performs the jobs as they fall due, one at a time, until ctx is done, and returns ctx.Err().
+// An attempt interrupted by the end of ctx is not counted, and is made again by the next Run.
+// It returns an error as well if the log cannot be written.
+func (q *Queue) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		j, h, wait, err := q.due()
+		if err != nil {
+			return err
+		}
+		if j == nil {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.wakeup:
+			case <-timer.C:
+			}
+			continue
+		}
+		if err := q.attempt(ctx, j, h); err != nil {
+			return err
+		}
+	}
+}
+
+// due returns the job due next with its handler, if it is due, or how long until it is.
+func (q *Queue) due() (*job, Handler, time.Duration, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.file == nil {
+		return nil, nil, 0, ErrClosed
+	}
+	var next *job
+	for _, j := range q.jobs {
+		if next == nil || j.next.Before(next.next) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, nil, time.Hour, nil
+	}
+	if wait := time.Until(next.next); wait > 0 {
+		return nil, nil, wait, nil
+	}
+	return next, q.handlers[next.Handler], 0, nil
+}
+
+// attempt makes an attempt of j with h and persists its outcome.
+func (q *Queue) attempt(ctx context.Context, j *job, h Handler) error {
+	var err error
+	if h == nil {
+		err = retry.Unrecoverable(errors.New("retryqueue: no handler named " + j.Handler))
+	} else {
+		err = h(ctx, j.Payload)
+	}
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	dead, werr := q.settle(j, err)
+	if werr != nil || !dead {
+		return werr
+	}
+	if q.deadLetter != nil {
+		q.deadLetter(ctx, j.Job, err)
+	}
+	return q.bury(j)
+}
+
+// settle persists the outcome err of an attempt of j, and reports whether j is to be given up on,
+// which is left to bury.
+func (q *Queue) settle(j *job, err error) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err == nil {
+		delete(q.jobs, j.ID)
+		return false, q.append(record{Op: opDone, ID: j.ID})
+	}
+	j.Attempts++
+	j.LastError = err.Error()
+	advice := q.policy.Advise(j.Attempts, err)
+	if !advice.Retry && advice.Reason == retry.ErrRetrySuspended.Error() {
+		// the failure rate gate only holds the retries back for a while; a durable job waits it out.
+		advice.Retry = true
+		advice.Delay = q.policy.Delay(j.Attempts)
+	}
+	if advice.Retry {
+		j.next = time.Now().Add(advice.Delay)
+		return false, q.append(j.retryRecord())
+	}
+	return true, nil
+}
+
+// bury removes j, given up on, from the queue and the log.
+func (q *Queue) bury(j *job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.jobs, j.ID)
+	return q.append(record{Op: opDead, ID: j.ID, Attempt: j.Attempts, Error: j.LastError})
+}
+
+// append writes rec to the log and syncs it, with q.mu held.
+func (q *Queue) append(rec record) error {
+	if q.file == nil {
+		return ErrClosed
+	}
+	if err := writeRecord(q.file, rec); err != nil {
+		return err
+	}
+	if err := q.file.Sync(); err != nil {
+		return err
+	}
+	q.records++
+	if q.records < q.compactAt {
+		return nil
+	}
+	file, n, err := compact(q.path, q.jobs)
+	if err != nil {
+		return err
+	}
+	q.file.Close()
+	q.file = file
+	q.records = n
+	q.compactAt = compactAt(n)
+	return nil
+}
+
+// Close closes the log. Run returns ErrClosed once its attempt in flight, if any, ends.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.file == nil {
+		return nil
+	}
+	err := q.file.Close()
+	q.file = nil
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+// Package retryredis classifies the errors of go-redis for retry.
+package retryredis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+)
+
+// transientPrefixes are the prefixes of the replies of a Redis server which a retry may get past.
+var transientPrefixes = []string{
+	"LOADING ",     // the server is loading the dataset
+	"READONLY ",    // the node was demoted to a replica
+	"CLUSTERDOWN ", // the cluster is not serving
+	"TRYAGAIN ",    // a multi-key command during resharding
+	"MOVED ",       // the slot moved to another node
+	"ASK ",         // the slot is being migrated
+	"MASTERDOWN ",  // the replica lost its master
+}
+
+// poolTimeout is the message of go-redis when no connection of the pool became free in time.
+const poolTimeout = "redis: connection pool timeout"
+
+// ShouldRetry is a shouldRetry predicate retrying the transient failures of go-redis:
+// replies of a server loading, read-only, in a cluster which is down or resharding, or which
+// redirect to another node, as well as network timeouts, lost connections and the exhaustion
+// of the connection pool. Errors of the context are not retried.
+func ShouldRetry(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var reply interface {
+		error
+		RedisError()
+	}
+	if errors.As(err, &reply) {
+		msg := reply.Error()
+		if msg == "ERR max number of clients reached" {
+			return true
+		}
+		for _, prefix := range transientPrefixes {
+			if strings.HasPrefix(msg, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(err.Error(), poolTimeout) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
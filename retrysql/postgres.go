@@ -0,0 +1,36 @@
+package retrysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"syscall"
+)
+
+// postgresStates are the SQLSTATE codes of Postgres failures which a retry may get past.
+var postgresStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P03": true, // cannot_connect_now
+}
+
+// PostgresShouldRetry is a shouldRetry predicate retrying the transient failures of Postgres:
+// serialization failures, deadlocks, a server starting up or shutting down, connection exceptions
+// (SQLSTATE class 08) and lost or refused connections. It recognizes the errors of both lib/pq
+// and pgx, which report the SQLSTATE with a SQLState method, as well as the errors pgx reports
+// safe to retry because nothing was sent to the server.
+func PostgresShouldRetry(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		code := state.SQLState()
+		return postgresStates[code] || (len(code) == 5 && code[:2] == "08")
+	}
+	var safe interface{ SafeToRetry() bool }
+	if errors.As(err, &safe) && safe.SafeToRetry() {
+		return true
+	}
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
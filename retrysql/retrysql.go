@@ -0,0 +1,40 @@
+// Package retrysql retries database/sql transactions.
+package retrysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// Tx runs fn in a transaction of db, committing it if fn succeeds, and retries the whole
+// transaction according to policy: every attempt begins a new transaction, and the one of
+// a failed attempt is rolled back first. shouldRetry of policy typically accepts serialization
+// failures and deadlocks only; an error of Commit it accepts must not leave the outcome of
+// the transaction unknown. fn must not commit or roll back tx itself. A panic in fn rolls
+// the transaction back and carries on.
+func Tx(ctx context.Context, db *sql.DB, policy retry.Retry, fn func(tx *sql.Tx) error) error {
+	return TxOptions(ctx, db, nil, policy, fn)
+}
+
+// TxOptions is like Tx, but begins the transactions with opts.
+func TxOptions(ctx context.Context, db *sql.DB, opts *sql.TxOptions, policy retry.Retry, fn func(tx *sql.Tx) error) error {
+	return policy.DoContext(ctx, func(ctx context.Context) error {
+		tx, err := db.BeginTx(ctx, opts)
+		if err != nil {
+			return err
+		}
+		done := false
+		defer func() {
+			if !done {
+				_ = tx.Rollback()
+			}
+		}()
+		if err := fn(tx); err != nil {
+			return err
+		}
+		done = true
+		return tx.Commit()
+	})
+}
@@ -0,0 +1,178 @@
+// Package retrytest provides a Retrier for tests, recording the attempts and delays of the code under test.
+package retrytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+)
+
+// Attempt is an attempt recorded by a Recorder.
+type Attempt struct {
+	Call     int           // the 1-based number of the call of Do the attempt belongs to
+	Attempt  int           // the 1-based number of the attempt in its call
+	Err      error         // the error of the attempt, nil if it succeeded
+	Injected bool          // whether Err was injected rather than returned by the function
+	Delay    time.Duration // the delay computed before the next attempt, 0 if there was none
+}
+
+// Recorder is a Retrier retrying like a policy, but without sleeping: its delays elapse at once on
+// a fake clock. It records every attempt with its error and the delay computed after it, so a test
+// can assert how the code under test retried, and can inject errors in place of the first attempts
+// of every call. The calls are serialized, so their attempts do not interleave.
+//
+// As with Retry.WithClock, the attempt timeouts and the max elapsed time still follow the system clock.
+type Recorder struct {
+	policy retry.Retry
+	serial sync.Mutex // held during a call
+
+	mu       sync.Mutex
+	now      time.Time
+	calls    int
+	attempts []Attempt
+	inject   []error
+}
+
+var _ retry.Retrier = (*Recorder)(nil)
+
+// NewRecorder creates a Recorder retrying like policy. Any Clock set on policy is replaced.
+func NewRecorder(policy retry.Retry) *Recorder {
+	rec := &Recorder{now: time.Unix(0, 0)}
+	rec.policy = policy.WithClock(recorderClock{rec})
+	return rec
+}
+
+// Inject makes the first attempts of every following call fail with errs, in order, without calling
+// the function. A nil error calls the function for its attempt. It returns rec.
+func (rec *Recorder) Inject(errs ...error) *Recorder {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.inject = errs
+	return rec
+}
+
+// Do retries f like the policy.
+func (rec *Recorder) Do(f func() error) error {
+	return rec.DoContext(context.Background(), func(context.Context) error {
+		return f()
+	})
+}
+
+// DoWithAttempt retries f like the policy, passing it the 1-based number of the attempt.
+func (rec *Recorder) DoWithAttempt(f func(attempt int) error) error {
+	return rec.doContext(context.Background(), func(_ context.Context, attempt int) error {
+		return f(attempt)
+	})
+}
+
+// DoContext retries f like the policy.
+func (rec *Recorder) DoContext(ctx context.Context, f func(context.Context) error) error {
+	return rec.doContext(ctx, func(ctx context.Context, _ int) error {
+		return f(ctx)
+	})
+}
+
+func (rec *Recorder) doContext(ctx context.Context, f func(ctx context.Context, attempt int) error) error {
+	rec.serial.Lock()
+	defer rec.serial.Unlock()
+	rec.mu.Lock()
+	rec.calls++
+	call := rec.calls
+	inject := rec.inject
+	rec.mu.Unlock()
+
+	attempt := 0
+	return rec.policy.DoContext(ctx, func(ctx context.Context) error {
+		attempt++
+		a := Attempt{Call: call, Attempt: attempt}
+		if attempt <= len(inject) && inject[attempt-1] != nil {
+			a.Err = inject[attempt-1]
+			a.Injected = true
+		} else {
+			a.Err = f(ctx, attempt)
+		}
+		rec.mu.Lock()
+		rec.attempts = append(rec.attempts, a)
+		rec.mu.Unlock()
+		return a.Err
+	})
+}
+
+// Calls returns the number of calls made.
+func (rec *Recorder) Calls() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.calls
+}
+
+// Attempts returns the attempts made by all the calls, in order.
+func (rec *Recorder) Attempts() []Attempt {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Attempt(nil), rec.attempts...)
+}
+
+// Retries returns the number of retries made by all the calls, i.e. the attempts but the first of every call.
+func (rec *Recorder) Retries() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.attempts) - rec.calls
+}
+
+// Delays returns the delays slept between the attempts of all the calls, in order.
+func (rec *Recorder) Delays() []time.Duration {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	var delays []time.Duration
+	for _, a := range rec.attempts {
+		if a.Delay > 0 {
+			delays = append(delays, a.Delay)
+		}
+	}
+	return delays
+}
+
+// Reset forgets the calls and attempts recorded, keeping the errors injected.
+func (rec *Recorder) Reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.calls = 0
+	rec.attempts = nil
+}
+
+// recorderClock fires every timer at once, recording its duration as the delay of the last attempt.
+type recorderClock struct {
+	rec *Recorder
+}
+
+func (c recorderClock) Now() time.Time {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+	return c.rec.now
+}
+
+func (c recorderClock) Sleep(d time.Duration) {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+	c.rec.now = c.rec.now.Add(d)
+}
+
+func (c recorderClock) NewTimer(d time.Duration) retry.Timer {
+	c.rec.mu.Lock()
+	c.rec.now = c.rec.now.Add(d)
+	if n := len(c.rec.attempts); n > 0 {
+		c.rec.attempts[n-1].Delay += d
+	}
+	now := c.rec.now
+	c.rec.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- now
+	return firedTimer(ch)
+}
+
+type firedTimer chan time.Time
+
+func (t firedTimer) C() <-chan time.Time { return t }
+func (t firedTimer) Stop() bool          { return false }
@@ -0,0 +1,12 @@
+module github.com/bluexlab/retry-go/retryzap
+
+go 1.21
+
+require (
+	github.com/bluexlab/retry-go v0.1.0
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+replace github.com/bluexlab/retry-go => ../
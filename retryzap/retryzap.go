@@ -0,0 +1,26 @@
+// Package retryzap adapts zap loggers to retry.
+package retryzap
+
+import (
+	"context"
+
+	"github.com/bluexlab/retry-go"
+	"go.uber.org/zap"
+)
+
+// New returns a retry.Logger logging to l, with the key-value pairs of the records as fields.
+func New(l *zap.Logger) retry.Logger {
+	return logger{l.Sugar()}
+}
+
+type logger struct {
+	l *zap.SugaredLogger
+}
+
+func (z logger) Debug(_ context.Context, msg string, keyvals ...any) {
+	z.l.Debugw(msg, keyvals...)
+}
+
+func (z logger) Warn(_ context.Context, msg string, keyvals ...any) {
+	z.l.Warnw(msg, keyvals...)
+}
@@ -0,0 +1,208 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSchedulerClosed is the abort cause of the operations a Scheduler drops when it is closed.
+var ErrSchedulerClosed = errors.New("scheduler closed")
+
+// Scheduler retries many concurrent operations without a goroutine for each: the attempts run on
+// a fixed pool of workers, and the operations waiting for their next attempt are parked on a shared
+// TimerWheel in between, costing a few words each instead of a sleeping goroutine.
+//
+// Only the predicate, the attempt limit, the delays and the history limit of the policy apply, as
+// well as the marks and delay hints on the errors. The context of an operation is checked before
+// every attempt, and an operation whose context is done while parked is woken up at once to give up.
+type Scheduler struct {
+	policy Retry
+	wheel  *TimerWheel
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	ready   []*scheduledOp
+	pending map[*scheduledOp]struct{}
+	closed  bool
+	workers sync.WaitGroup
+}
+
+type scheduledOp struct {
+	ctx  context.Context
+	f    func(context.Context) error
+	done chan error
+
+	parked    atomic.Bool
+	stopWatch func() bool // stops watching ctx
+	attempt   int
+	lastErr   error
+	hist      history
+	begin     time.Time
+	slept     time.Duration
+}
+
+// NewScheduler creates a Scheduler retrying with policy, running the attempts on workers goroutines
+// and parking the operations waiting for their next attempt on w. Close must be called to release
+// the workers; w is not stopped with it. It panics with ErrInvalidConfig if policy is invalid.
+func NewScheduler(policy Retry, w *TimerWheel, workers int) *Scheduler {
+	if err := policy.Validate(); err != nil {
+		panic(err)
+	}
+	if workers <= 0 {
+		panic("workers must be greater than 0")
+	}
+	s := &Scheduler{policy: policy, wheel: w, pending: map[*scheduledOp]struct{}{}}
+	s.cond = sync.NewCond(&s.mu)
+	s.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// Submit schedules f for its first attempt and returns a channel receiving its outcome, like
+// Retry.DoAsync: nil on success, or the error DoContext would return. The channel is buffered.
+// An operation submitted after Close ends with ErrAborted matching ErrSchedulerClosed.
+func (s *Scheduler) Submit(ctx context.Context, f func(context.Context) error) <-chan error {
+	op := &scheduledOp{
+		ctx:   ctx,
+		f:     f,
+		done:  make(chan error, 1),
+		hist:  history{limit: s.policy.historyLimit},
+		begin: time.Now(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		op.done <- &ErrAborted{Cause: ErrSchedulerClosed}
+		return op.done
+	}
+	s.pending[op] = struct{}{}
+	op.stopWatch = context.AfterFunc(ctx, func() { s.wake(op) })
+	s.enqueue(op)
+	return op.done
+}
+
+// Close stops accepting operations, lets the attempts in flight end, and waits for the workers to exit.
+// The operations not done by then end with ErrAborted matching ErrSchedulerClosed.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.ready = nil
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	s.workers.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for op := range s.pending {
+		s.finish(op, &ErrAborted{Cause: ErrSchedulerClosed, Err: op.lastErr, Attempts: op.hist.attempts(), Dropped: op.hist.dropped})
+	}
+}
+
+func (s *Scheduler) work() {
+	defer s.workers.Done()
+	for {
+		s.mu.Lock()
+		for len(s.ready) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		op := s.ready[0]
+		s.ready[0] = nil
+		s.ready = s.ready[1:]
+		s.mu.Unlock()
+		s.step(op)
+	}
+}
+
+// step makes the next attempt of op, and either finishes it or parks it until the attempt after.
+func (s *Scheduler) step(op *scheduledOp) {
+	r := s.policy
+	if err := op.ctx.Err(); err != nil {
+		s.done(op, &ErrAborted{Cause: err, Err: op.lastErr, Attempts: op.hist.attempts(), Dropped: op.hist.dropped})
+		return
+	}
+	op.attempt++
+	start := time.Now()
+	err := op.f(op.ctx)
+	if err == nil {
+		s.done(op, nil)
+		return
+	}
+	if !r.retries(err) {
+		s.done(op, unmark(err))
+		return
+	}
+	op.lastErr = err
+	op.hist.add(AttemptError{Attempt: op.attempt, Time: time.Now(), Err: err, Duration: time.Since(start)})
+	maxAttempt := r.maxAttempt
+	if r.unlimited {
+		maxAttempt = math.MaxInt
+	}
+	if op.attempt >= maxAttempt {
+		s.done(op, &ErrMaxAttemptExceeded{
+			Err:          err,
+			Attempts:     op.hist.attempts(),
+			Dropped:      op.hist.dropped,
+			AttemptsMade: op.attempt,
+			Elapsed:      time.Since(op.begin),
+			Slept:        op.slept,
+		})
+		return
+	}
+	wait := hintedDelay(err)
+	if wait <= 0 {
+		wait = r.Delay(op.attempt)
+	}
+	op.slept += wait
+	op.hist.setDelay(wait)
+	op.parked.Store(true)
+	if op.ctx.Err() != nil {
+		// done before it was parked, so the watch may have missed it.
+		s.wake(op)
+		return
+	}
+	s.wheel.afterFunc(wait, func() { s.wake(op) })
+}
+
+// wake queues op for its next attempt, unless it is not parked, e.g. already woken up by the wheel
+// or its context.
+func (s *Scheduler) wake(op *scheduledOp) {
+	if !op.parked.CompareAndSwap(true, false) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.enqueue(op)
+	}
+}
+
+func (s *Scheduler) enqueue(op *scheduledOp) {
+	s.ready = append(s.ready, op)
+	s.cond.Signal()
+}
+
+func (s *Scheduler) done(op *scheduledOp, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finish(op, err)
+}
+
+// finish delivers the outcome of op, with s.mu held.
+func (s *Scheduler) finish(op *scheduledOp, err error) {
+	if _, ok := s.pending[op]; !ok {
+		return
+	}
+	delete(s.pending, op)
+	op.stopWatch()
+	op.done <- err
+}
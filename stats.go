@@ -0,0 +1,59 @@
+package retry
+
+import "sync/atomic"
+
+// Stats is a snapshot of the cumulative statistics of a Retry, see WithStats.
+type Stats struct {
+	Calls       uint64 // calls of Do
+	Attempts    uint64 // attempts made, the first ones included
+	Retries     uint64 // attempts made after a failure
+	Successes   uint64 // calls which succeeded
+	Exhaustions uint64 // calls which exhausted their attempts
+	Aborts      uint64 // calls which gave up with ErrAborted
+	Failures    uint64 // calls which failed with an error shouldRetry declined
+}
+
+// AvgAttempts returns the average number of attempts per call, or 0 if there was no call.
+func (s Stats) AvgAttempts() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Attempts) / float64(s.Calls)
+}
+
+// stats holds the counters of a Retry.
+type stats struct {
+	calls       atomic.Uint64
+	attempts    atomic.Uint64
+	retries     atomic.Uint64
+	successes   atomic.Uint64
+	exhaustions atomic.Uint64
+	aborts      atomic.Uint64
+	failures    atomic.Uint64
+}
+
+// record records the outcome err of a call.
+func (s *stats) record(err error) {
+	switch err.(type) {
+	case nil:
+		s.successes.Add(1)
+	case *ErrMaxAttemptExceeded:
+		s.exhaustions.Add(1)
+	case *ErrAborted:
+		s.aborts.Add(1)
+	default:
+		s.failures.Add(1)
+	}
+}
+
+func (s *stats) snapshot() Stats {
+	return Stats{
+		Calls:       s.calls.Load(),
+		Attempts:    s.attempts.Load(),
+		Retries:     s.retries.Load(),
+		Successes:   s.successes.Load(),
+		Exhaustions: s.exhaustions.Load(),
+		Aborts:      s.aborts.Load(),
+		Failures:    s.failures.Load(),
+	}
+}
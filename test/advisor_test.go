@@ -0,0 +1,30 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdvisor(t *testing.T) {
+	transient := errors.New("503")
+	r := retry.New(func(e error) bool { return e == transient }, 3, 10, 100).WithNoJitter()
+	a := retry.NewAdvisor(r)
+
+	assert.Equal(t, retry.Advice{Retry: true, Delay: 10 * time.Millisecond, Attempt: 1}, a.Advise(transient))
+	assert.Equal(t, retry.Advice{Retry: true, Delay: 20 * time.Millisecond, Attempt: 2}, a.Advise(transient))
+	advice := a.Advise(transient)
+	assert.False(t, advice.Retry)
+	assert.Equal(t, "attempts exhausted", advice.Reason)
+
+	a.Reset()
+	advice = a.Advise(errors.New("400"))
+	assert.False(t, advice.Retry)
+	assert.Equal(t, "not retryable", advice.Reason)
+
+	assert.False(t, a.Advise(nil).Retry)
+	assert.Equal(t, 1, a.Advise(transient).Attempt)
+}
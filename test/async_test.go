@@ -0,0 +1,53 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoAsync(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	var count atomic.Int32
+	ok := r.DoAsync(context.Background(), func(ctx context.Context) error {
+		if count.Add(1) < 2 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	failed := r.DoAsync(context.Background(), func(ctx context.Context) error {
+		return errors.New("fail")
+	})
+
+	var errs []error
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-ok:
+			assert.NoError(t, err)
+			errs = append(errs, err)
+		case err := <-failed:
+			assert.ErrorIs(t, err, retry.ErrExhausted)
+			errs = append(errs, err)
+		}
+	}
+	assert.Len(t, errs, 2)
+}
+
+func TestDoValueAsync(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	res := <-retry.DoValueAsync(context.Background(), r, func(ctx context.Context) (string, error) {
+		return "value", nil
+	})
+	assert.Equal(t, retry.Result[string]{Value: "value"}, res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = <-retry.DoValueAsync(ctx, r, func(ctx context.Context) (string, error) {
+		return "", errors.New("fail")
+	})
+	assert.ErrorIs(t, res.Err, retry.ErrCanceled)
+}
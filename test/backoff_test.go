@@ -0,0 +1,44 @@
+package test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 10, 10, 1000).WithNoJitter()
+
+	constant := r.WithBackoff(retry.ConstantBackoff(50 * time.Millisecond))
+	assert.Equal(t, 50*time.Millisecond, constant.Delay(1))
+	assert.Equal(t, 50*time.Millisecond, constant.Delay(10))
+
+	linear := r.WithBackoff(retry.LinearBackoff(100*time.Millisecond, 50*time.Millisecond))
+	assert.Equal(t, 100*time.Millisecond, linear.Delay(1))
+	assert.Equal(t, 150*time.Millisecond, linear.Delay(2))
+	assert.Equal(t, 550*time.Millisecond, linear.Delay(10))
+	assert.Equal(t, time.Duration(math.MaxInt64), retry.LinearBackoff(time.Hour, time.Hour)(math.MaxInt64))
+
+	// jitter still applies.
+	jittered := linear.WithJitter(retry.EqualJitter)
+	for i := 0; i < 100; i++ {
+		d := jittered.Delay(2)
+		assert.GreaterOrEqual(t, d, 75*time.Millisecond)
+		assert.LessOrEqual(t, d, 150*time.Millisecond)
+	}
+
+	var delays []time.Duration
+	err := retry.New(func(e error) bool { return true }, 4, 0, 0).
+		WithNoJitter().
+		WithBackoff(retry.LinearBackoff(time.Millisecond, time.Millisecond)).
+		Do(func() error { return assert.AnError })
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	for _, a := range exceeded.Attempts {
+		delays = append(delays, a.Delay)
+	}
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 0}, delays)
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrappersDoNotAllocate(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1)
+	add := func(a, b int) (int, error) { return a + b, nil }
+	split := func() (int, string, error) { return 1, "a", nil }
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = r.Do(func() error { return nil })
+	}))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _ = retry.Retry2Func2(r, add, 1, 2)
+	}))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _, _ = retry.Retry3(r, split)
+	}))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _ = retry.DoValue(context.Background(), r, func(context.Context) (int, error) { return add(1, 2) })
+	}))
+}
+
+func BenchmarkDo(b *testing.B) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = r.Do(func() error { return nil })
+	}
+}
+
+func BenchmarkRetry2Func2(b *testing.B) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1)
+	add := func(a, b int) (int, error) { return a + b, nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = retry.Retry2Func2(r, add, 1, 2)
+	}
+}
+
+func BenchmarkRetry3(b *testing.B) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1)
+	split := func() (int, string, error) { return 1, "a", nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = retry.Retry3(r, split)
+	}
+}
+
+func BenchmarkDelayParallel(b *testing.B) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1000)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = r.Delay(3)
+		}
+	})
+}
+
+func BenchmarkDelayParallelLockedSource(b *testing.B) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1000).WithRandSource(rand.NewSource(1))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = r.Delay(3)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := retry.NewCircuitBreaker(2, 50*time.Millisecond)
+	r := retry.New(func(error) bool { return true }, 2, 0, 0).WithCircuitBreaker(b)
+
+	count := 0
+	fail := func() error {
+		count = count + 1
+		return errors.New("fail")
+	}
+	assert.ErrorIs(t, r.Do(fail), retry.ErrExhausted)
+	assert.Equal(t, retry.CircuitClosed, b.State())
+	assert.ErrorIs(t, r.Do(fail), retry.ErrExhausted)
+	assert.Equal(t, retry.CircuitOpen, b.State())
+	assert.Equal(t, 4, count)
+
+	// fails fast while open.
+	count = 0
+	err := r.Do(fail)
+	assert.ErrorIs(t, err, retry.ErrCircuitOpen)
+	assert.Equal(t, 0, count)
+
+	// a failed probe opens it again.
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, retry.CircuitHalfOpen, b.State())
+	assert.ErrorIs(t, r.Do(fail), retry.ErrExhausted)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, retry.CircuitOpen, b.State())
+
+	// a successful probe closes it.
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, r.Do(func() error { return nil }))
+	assert.Equal(t, retry.CircuitClosed, b.State())
+	assert.Equal(t, "closed", b.State().String())
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	b := retry.NewCircuitBreaker(1, 10*time.Millisecond)
+	r := retry.New(func(error) bool { return true }, 1, 0, 0).WithCircuitBreaker(b)
+	assert.Error(t, r.Do(func() error { return errors.New("fail") }))
+	time.Sleep(20 * time.Millisecond)
+
+	probing := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- r.Do(func() error {
+			close(probing)
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}()
+	<-probing
+	assert.ErrorIs(t, r.Do(func() error { return nil }), retry.ErrCircuitOpen)
+	assert.NoError(t, <-done)
+	assert.Equal(t, retry.CircuitClosed, b.State())
+}
+
+func TestCircuitBreakerIgnoresPermanentErrors(t *testing.T) {
+	b := retry.NewCircuitBreaker(1, time.Hour)
+	r := retry.New(func(error) bool { return false }, 3, 0, 0).WithCircuitBreaker(b)
+	assert.Error(t, r.Do(func() error { return errors.New("bad request") }))
+	assert.Equal(t, retry.CircuitClosed, b.State())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := retry.New(func(error) bool { return true }, 3, 0, 0).WithCircuitBreaker(b).DoContext(ctx, func(ctx context.Context) error {
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrCanceled)
+	assert.Equal(t, retry.CircuitClosed, b.State())
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	b := retry.NewCircuitBreaker(0, time.Hour).WithFailureRate(time.Second, 0.5, 4)
+	r := retry.New(func(error) bool { return true }, 1, 0, 0).WithCircuitBreaker(b)
+	ok := func() error { return nil }
+	fail := func() error { return errors.New("fail") }
+
+	assert.NoError(t, r.Do(ok))
+	assert.Error(t, r.Do(fail))
+	assert.NoError(t, r.Do(ok))
+	assert.Error(t, r.Do(fail))
+	assert.Equal(t, retry.CircuitClosed, b.State())
+	assert.Error(t, r.Do(fail))
+	assert.Equal(t, retry.CircuitOpen, b.State())
+
+	b.Reset()
+	assert.Equal(t, retry.CircuitClosed, b.State())
+	assert.NoError(t, r.Do(ok))
+}
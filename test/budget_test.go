@@ -0,0 +1,37 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudget(t *testing.T) {
+	b := retry.NewRetryBudget(0.5, 2)
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithRetryBudget(b)
+
+	count := 0
+	fail := func() error {
+		count = count + 1
+		return errors.New("fail")
+	}
+	// the call deposits nothing on a full bucket, and 2 retries drain it.
+	assert.ErrorIs(t, r.Do(fail), retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 0.0, b.Tokens())
+
+	count = 0
+	err := r.Do(fail)
+	assert.ErrorIs(t, err, retry.ErrBudgetExhausted)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 0.5, b.Tokens())
+
+	// 2 calls later, there is a token for one retry.
+	assert.NoError(t, r.Do(func() error { return nil }))
+	assert.Equal(t, 1.0, b.Tokens())
+	count = 0
+	assert.ErrorIs(t, r.Do(fail), retry.ErrBudgetExhausted)
+	assert.Equal(t, 2, count)
+}
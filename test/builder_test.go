@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilders(t *testing.T) {
+	base := retry.NewWithDurations(func(error) bool { return true }, 3, 100*time.Millisecond, time.Second).WithNoJitter()
+
+	r := base.WithMaxDelay(50 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, r.Delay(1))
+	assert.Equal(t, 100*time.Millisecond, base.Delay(1))
+
+	r = base.WithInitialDelay(time.Millisecond)
+	assert.Equal(t, time.Millisecond, r.Delay(1))
+	assert.Equal(t, time.Second, r.Delay(20))
+
+	fail := errors.New("fail")
+	var wg sync.WaitGroup
+	counts := make([]int, 3)
+	for i := range counts {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := base.WithDelays(0, 0).WithMaxAttempts(i + 1)
+			_ = r.Do(func() error {
+				counts[i]++
+				return fail
+			})
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []int{1, 2, 3}, counts)
+
+	count := 0
+	err := base.WithDelays(0, 0).WithRetryIf(func(err error) bool { return !errors.Is(err, fail) }).Do(func() error {
+		count++
+		return fail
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 1, count)
+
+	count = 0
+	_ = base.WithDelays(0, 0).WithUnlimitedAttempts().WithMaxAttempts(2).Do(func() error {
+		count++
+		return fail
+	})
+	assert.Equal(t, 2, count)
+}
+
+func TestZeroRetry(t *testing.T) {
+	r := retry.Retry{}.WithRetryIf(func(error) bool { return true }).WithMaxAttempts(3).WithDelays(time.Millisecond, 2*time.Millisecond)
+	assert.NoError(t, r.Validate())
+
+	count := 0
+	err := r.Do(func() error {
+		count++
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+
+	fo := retry.NewWeightedFailover(r, retry.AdvanceOnExhaustion, []string{"a", "b"}, []int{1, 1})
+	var tried []string
+	_ = fo.DoContext(context.Background(), func(ctx context.Context, endpoint string) error {
+		tried = append(tried, endpoint)
+		return errors.New("down")
+	})
+	assert.Len(t, tried, 6)
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureCache(t *testing.T) {
+	needRetry := errors.New("ALSKDJFALKDSJF")
+	forbidden := errors.New("403 forbidden")
+	r := retry.New(func(e error) bool { return e == needRetry }, 3, 0, 0)
+	c := retry.NewFailureCache(50 * time.Millisecond)
+	ctx := context.Background()
+
+	count := 0
+	fail := func(context.Context) error {
+		count = count + 1
+		return forbidden
+	}
+	assert.Equal(t, forbidden, c.DoContext(ctx, r, "tenant-a", fail))
+	assert.Equal(t, forbidden, c.DoContext(ctx, r, "tenant-a", fail))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, forbidden, c.Get("tenant-a"))
+
+	// other keys are not affected.
+	assert.NoError(t, c.DoContext(ctx, r, "tenant-b", func(context.Context) error { return nil }))
+
+	// exhausted retries are not permanent failures.
+	count = 0
+	retrying := func(context.Context) error {
+		count = count + 1
+		return needRetry
+	}
+	assert.ErrorIs(t, c.DoContext(ctx, r, "tenant-c", retrying), retry.ErrExhausted)
+	assert.ErrorIs(t, c.DoContext(ctx, r, "tenant-c", retrying), retry.ErrExhausted)
+	assert.Equal(t, 6, count)
+
+	c.Forget("tenant-a")
+	assert.NoError(t, c.Get("tenant-a"))
+
+	count = 0
+	assert.Equal(t, forbidden, c.DoContext(ctx, r, "tenant-a", fail))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, forbidden, c.DoContext(ctx, r, "tenant-a", fail))
+	assert.Equal(t, 2, count)
+}
+
+func TestFailureCacheIgnoresContextErrors(t *testing.T) {
+	r := retry.New(func(e error) bool { return false }, 3, 0, 0)
+	c := retry.NewFailureCache(time.Minute)
+
+	// a caller giving up does not fail the callers after it.
+	ctx, cancel := context.WithCancel(context.Background())
+	err := c.DoContext(ctx, r, "tenant-a", func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, c.Get("tenant-a"))
+
+	// nor does a timeout of the operation itself.
+	err = c.DoContext(context.Background(), r, "tenant-a", func(context.Context) error {
+		return fmt.Errorf("query: %w", context.DeadlineExceeded)
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, c.Get("tenant-a"))
+
+	count := 0
+	assert.NoError(t, c.DoContext(context.Background(), r, "tenant-a", func(context.Context) error {
+		count = count + 1
+		return nil
+	}))
+	assert.Equal(t, 1, count)
+}
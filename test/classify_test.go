@@ -0,0 +1,61 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTemporaryNetErr(t *testing.T) {
+	assert.True(t, retry.IsTemporaryNetErr(&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}))
+	assert.True(t, retry.IsTemporaryNetErr(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}))
+	assert.True(t, retry.IsTemporaryNetErr(fmt.Errorf("read body: %w", io.ErrUnexpectedEOF)))
+	assert.True(t, retry.IsTemporaryNetErr(&net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}))
+	assert.True(t, retry.IsTemporaryNetErr(os.ErrDeadlineExceeded))
+	assert.False(t, retry.IsTemporaryNetErr(&net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}))
+	assert.False(t, retry.IsTemporaryNetErr(&net.AddrError{Err: "missing port in address", Addr: "example.com"}))
+	assert.False(t, retry.IsTemporaryNetErr(context.DeadlineExceeded))
+	assert.False(t, retry.IsTemporaryNetErr(errors.New("plain")))
+	assert.False(t, retry.IsTemporaryNetErr(nil))
+
+	// a real timeout.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	assert.True(t, retry.IsTemporaryNetErr(err))
+}
+
+func TestIsTemporaryDNSErr(t *testing.T) {
+	assert.True(t, retry.IsTemporaryDNSErr(&net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}))
+	assert.True(t, retry.IsTemporaryDNSErr(fmt.Errorf("dial: %w", &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true})))
+	assert.False(t, retry.IsTemporaryDNSErr(&net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}))
+	assert.False(t, retry.IsTemporaryDNSErr(&net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true, IsTemporary: true}))
+	assert.False(t, retry.IsTemporaryDNSErr(&net.DNSError{Err: "unrecognized reply", Name: "example.com"}))
+	assert.False(t, retry.IsTemporaryDNSErr(os.ErrDeadlineExceeded))
+
+	// DoResolved gives up on a host which does not exist.
+	count := 0
+	resolve := func(ctx context.Context, target string) ([]string, error) {
+		count = count + 1
+		return nil, &net.DNSError{Err: "no such host", Name: target, IsNotFound: true}
+	}
+	err := retry.New(retry.IsTemporaryDNSErr, 3, 0, 0).DoResolved(context.Background(), "nowhere.invalid:80", resolve, func(ctx context.Context, addr string) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+}
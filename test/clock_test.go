@@ -0,0 +1,68 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock fires every timer at once, advancing its time by the duration of the timer.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	waited []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) retry.Timer {
+	c.Sleep(d)
+	c.mu.Lock()
+	c.waited = append(c.waited, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return fakeTimer(ch)
+}
+
+type fakeTimer chan time.Time
+
+func (t fakeTimer) C() <-chan time.Time { return t }
+func (t fakeTimer) Stop() bool          { return false }
+
+func TestWithClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := retry.New(func(e error) bool { return true }, 10, 1000, 60000).WithNoJitter().WithClock(clock)
+
+	start := time.Now()
+	err := r.Do(func() error {
+		clock.Sleep(time.Second)
+		return errors.New("fail")
+	})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second,
+		32 * time.Second, time.Minute, time.Minute, time.Minute,
+	}, clock.waited)
+
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 10*time.Second+243*time.Second, exceeded.Elapsed)
+	assert.Equal(t, 243*time.Second, exceeded.Slept)
+	assert.Equal(t, time.Second, exceeded.Attempts[0].Duration)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), exceeded.Attempts[0].Time)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildRetry builds the retry command into a temporary directory and returns its path.
+func buildRetry(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "retry")
+	out, err := exec.Command("go", "build", "-o", bin, "github.com/bluexlab/retry-go/cmd/retry").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runRetry runs the retry command with args, and returns its exit code and standard error.
+func runRetry(t *testing.T, bin string, args ...string) (int, string) {
+	t.Helper()
+	var stderr strings.Builder
+	cmd := exec.Command(bin, args...)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), stderr.String()
+	}
+	assert.NoError(t, err)
+	return 0, stderr.String()
+}
+
+func TestRetryCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell")
+	}
+	bin := buildRetry(t)
+	fast := []string{"-init-delay", "1ms", "-max-delay", "1ms", "-attempts", "3"}
+
+	// the exit code of the last run is propagated.
+	code, stderr := runRetry(t, bin, append(fast, "--", "sh", "-c", "exit 3")...)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, 3, strings.Count(stderr, "exit status 3"))
+
+	code, _ = runRetry(t, bin, append(fast, "--", "true")...)
+	assert.Equal(t, 0, code)
+
+	// only the exit codes of -retry-on are retried.
+	code, stderr = runRetry(t, bin, append(fast, "-retry-on", "3, 5", "--", "sh", "-c", "exit 4")...)
+	assert.Equal(t, 4, code)
+	assert.Equal(t, 1, strings.Count(stderr, "exit status 4"))
+
+	code, stderr = runRetry(t, bin, append(fast, "-retry-on", "3,5", "--", "sh", "-c", "exit 5")...)
+	assert.Equal(t, 5, code)
+	assert.Equal(t, 3, strings.Count(stderr, "exit status 5"))
+
+	// the command succeeding on a retry.
+	counter := filepath.Join(t.TempDir(), "counter")
+	code, _ = runRetry(t, bin, append(fast, "--", "sh", "-c", `echo x >> "$0"; [ $(wc -l < "$0") -ge 2 ]`, counter)...)
+	assert.Equal(t, 0, code)
+
+	// a command which cannot be run is not retried.
+	code, stderr = runRetry(t, bin, append(fast, "--", "/nonexistent/command")...)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, 1, strings.Count(stderr, "retry: attempt"))
+
+	// invalid flags.
+	for _, args := range [][]string{
+		{"-retry-on", "3,x", "--", "true"},
+		{"-jitter", "some", "--", "true"},
+		{"-backoff", "fibonacci", "--", "true"},
+		{"-attempts", "0", "--", "true"},
+		{"-init-delay", "2s", "-max-delay", "1s", "--", "true"},
+		{"-attempts", "3"},
+	} {
+		code, _ = runRetry(t, bin, args...)
+		assert.Equal(t, 2, code, args)
+	}
+}
+
+func TestRetryCommandPolicy(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell")
+	}
+	bin := buildRetry(t)
+
+	code, stderr := runRetry(t, bin, "-backoff", "linear", "-step", "1ms", "-init-delay", "1ms", "-max-delay", "3ms", "-jitter", "equal", "-attempts", "4", "--", "false")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, 4, strings.Count(stderr, "retry: attempt"))
+
+	policy := filepath.Join(t.TempDir(), "policy.json")
+	assert.NoError(t, os.WriteFile(policy, []byte(`{"maxAttempts": 2, "initialDelay": "1ms", "maxDelay": "1ms", "backoff": "constant", "jitter": "none"}`), 0o600))
+	code, stderr = runRetry(t, bin, "-policy", policy, "--", "false")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, 2, strings.Count(stderr, "retry: attempt"))
+
+	assert.NoError(t, os.WriteFile(policy, []byte(`{"maxAttempts": 2, "maxDelay": "1 ms"}`), 0o600))
+	code, _ = runRetry(t, bin, "-policy", policy, "--", "false")
+	assert.Equal(t, 2, code)
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalescer(t *testing.T) {
+	c := retry.NewCoalescer()
+	r := retry.New(func(e error) bool { return true }, 3, 1, 1)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	f := func(context.Context) error {
+		if calls.Add(1) == 1 {
+			<-release
+			return errors.New("first attempt fails")
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.DoContext(ctx, r, "order-1", f)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	// once done, the key runs anew.
+	assert.NoError(t, c.DoContext(ctx, r, "order-1", f))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCoalescerCancel(t *testing.T) {
+	c := retry.NewCoalescer()
+	r := retry.New(func(e error) bool { return true }, 3, 0, 0)
+	canceled := make(chan struct{})
+	f := func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	done := make(chan error, 2)
+	go func() { done <- c.DoContext(ctx1, r, "k", f) }()
+	go func() { done <- c.DoContext(ctx2, r, "k", f) }()
+	time.Sleep(10 * time.Millisecond)
+
+	// the execution survives as long as somebody still waits on it.
+	cancel1()
+	assert.ErrorIs(t, <-done, retry.ErrCanceled)
+	select {
+	case <-canceled:
+		t.Fatal("execution canceled while a caller still waits")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel2()
+	assert.ErrorIs(t, <-done, retry.ErrCanceled)
+	<-canceled
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMaxConcurrent(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithMaxConcurrent(2)
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := r.Do(func() error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestWithMaxConcurrentAborted(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithMaxConcurrent(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = r.Do(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	called := false
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.False(t, called)
+}
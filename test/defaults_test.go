@@ -0,0 +1,58 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPolicies(t *testing.T) {
+	r := retry.Default().WithNoJitter()
+	assert.Equal(t, 100*time.Millisecond, r.Delay(1))
+	assert.Equal(t, 5*time.Second, r.Delay(10))
+
+	r = retry.AggressiveShort().WithNoJitter()
+	assert.Equal(t, 5*time.Millisecond, r.Delay(1))
+	assert.Equal(t, 100*time.Millisecond, r.Delay(9))
+
+	r = retry.SlowPoll().WithNoJitter()
+	assert.Equal(t, time.Second, r.Delay(1))
+	assert.Equal(t, 30*time.Second, r.Delay(59))
+
+	for _, r := range []retry.Retry{retry.Default(), retry.AggressiveShort(), retry.SlowPoll()} {
+		count := 0
+		err := r.WithDelays(0, 0).Do(func() error {
+			count++
+			if count == 1 {
+				return errors.New("flaky")
+			}
+			return context.Canceled
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 2, count)
+	}
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	r := retry.Network().WithDelays(0, 0)
+	count := 0
+	err := r.Do(func() error {
+		count++
+		return syscall.ECONNRESET
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 6, count)
+
+	count = 0
+	err = r.Do(func() error {
+		count++
+		return errors.New("bad request")
+	})
+	assert.EqualError(t, err, "bad request")
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEach(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	var mu sync.Mutex
+	calls := map[int]int{}
+	errs := retry.Each(context.Background(), r, []int{1, 2, 3, 4}, 2, func(ctx context.Context, item int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[item]++
+		if item%2 == 0 && calls[item] < 3 {
+			return errors.New("throttled")
+		}
+		return nil
+	})
+	assert.Nil(t, errs)
+	assert.Equal(t, map[int]int{1: 1, 2: 3, 3: 1, 4: 3}, calls)
+}
+
+func TestEachFailures(t *testing.T) {
+	r := retry.New(func(err error) bool { return err.Error() == "throttled" }, 2, 0, 0)
+	var calls atomic.Int32
+	errs := retry.Each(context.Background(), r, []string{"ok", "bad", "throttled"}, 0, func(ctx context.Context, item string) error {
+		calls.Add(1)
+		if item == "ok" {
+			return nil
+		}
+		return errors.New(item)
+	})
+	assert.Len(t, errs, 3)
+	assert.NoError(t, errs[0])
+	assert.EqualError(t, errs[1], "bad")
+	assert.ErrorIs(t, errs[2], retry.ErrExhausted)
+	assert.EqualValues(t, 4, calls.Load())
+}
+
+func TestEachConcurrency(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 1, 0, 0)
+	var running, peak atomic.Int32
+	errs := retry.Each(context.Background(), r, make([]int, 8), 3, func(ctx context.Context, item int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+	assert.Nil(t, errs)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestEachAborted(t *testing.T) {
+	r := retry.NewWithDurations(func(error) bool { return true }, 3, time.Hour, time.Hour).WithNoJitter()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	errs := retry.Each(ctx, r, []int{1, 2}, 0, func(ctx context.Context, item int) error {
+		if item == 1 {
+			return nil
+		}
+		return errors.New("throttled")
+	})
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], retry.ErrDeadlineExceeded)
+}
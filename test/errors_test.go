@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinguishFinalErrors(t *testing.T) {
+	needRetry := errors.New("ALSKDJFALKDSJF")
+	r := retry.New(func(e error) bool { return e == needRetry }, 3, 1, 1)
+
+	err := r.Do(func() error { return needRetry })
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.NotErrorIs(t, err, retry.ErrCanceled)
+	assert.NotErrorIs(t, err, retry.ErrDeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	err = r.DoContext(ctx, func(context.Context) error {
+		count = count + 1
+		cancel()
+		return needRetry
+	})
+	assert.Equal(t, 1, count)
+	assert.ErrorIs(t, err, retry.ErrCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, needRetry)
+	assert.NotErrorIs(t, err, retry.ErrDeadlineExceeded)
+	var aborted *retry.ErrAborted
+	assert.ErrorAs(t, err, &aborted)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = retry.New(func(e error) bool { return e == needRetry }, 3, 10000, 10000).DoContext(ctx, func(context.Context) error {
+		return needRetry
+	})
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, retry.ErrCanceled)
+}
+
+func TestErrExhausted(t *testing.T) {
+	needRetry := errors.New("ALSKDJFALKDSJF")
+	r := retry.New(func(e error) bool { return e == needRetry }, 2, 1, 1)
+
+	err := r.Do(func() error { return needRetry })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, needRetry)
+
+	wrapped := fmt.Errorf("layer 2: %w", fmt.Errorf("layer 1: %w", err))
+	assert.ErrorIs(t, wrapped, retry.ErrExhausted)
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, wrapped, &exceeded)
+	assert.Equal(t, needRetry, exceeded.Err)
+
+	err = r.Do(func() error { return errors.New("DON'T RETRY") })
+	assert.NotErrorIs(t, err, retry.ErrExhausted)
+}
+
+func TestAttemptErrors(t *testing.T) {
+	errs := []error{errors.New("first"), errors.New("second"), errors.New("third")}
+	r := retry.New(func(error) bool { return true }, 3, 1, 1)
+
+	count := 0
+	start := time.Now()
+	err := r.Do(func() error {
+		count = count + 1
+		return errs[count-1]
+	})
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, errs[2], exceeded.Err)
+	assert.Len(t, exceeded.Attempts, 3)
+	for i, a := range exceeded.Attempts {
+		assert.Equal(t, i+1, a.Attempt)
+		assert.Equal(t, errs[i], a.Err)
+		assert.False(t, a.Time.Before(start))
+		assert.ErrorIs(t, &exceeded.Attempts[i], errs[i])
+	}
+	assert.Equal(t, "attempt 2: second", exceeded.Attempts[1].Error())
+}
+
+func TestAttemptTimeIsMonotonic(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 2, 0, 0)
+	err := r.Do(func() error { return errors.New("fail") })
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	for _, a := range exceeded.Attempts {
+		// the monotonic clock reading is printed as "m=±<value>".
+		assert.Contains(t, a.Time.String(), "m=")
+	}
+}
+
+func TestBudgetsIgnoreWallClockJumps(t *testing.T) {
+	// the attempts step the time of the clock an hour forward or two hours backward, the way
+	// a wall clock being set does; the budgets keep counting the delays and the real time.
+	jump := func(clock *fakeClock, n int) {
+		if n%2 == 0 {
+			clock.Sleep(time.Hour)
+		} else {
+			clock.Sleep(-2 * time.Hour)
+		}
+	}
+
+	t.Run("max total sleep", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		r := retry.New(func(error) bool { return true }, 10, 1000, 1000).
+			WithNoJitter().WithClock(clock).WithMaxTotalSleep(2500 * time.Millisecond)
+		count := 0
+		err := r.Do(func() error {
+			jump(clock, count)
+			count++
+			return errors.New("fail")
+		})
+		assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+		assert.Equal(t, 4, count)
+		assert.Equal(t, []time.Duration{time.Second, time.Second, 500 * time.Millisecond}, clock.waited)
+	})
+
+	t.Run("max elapsed time", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		r := retry.New(func(error) bool { return true }, 1000, 0, 0).
+			WithClock(clock).WithMaxElapsedTime(50 * time.Millisecond)
+		count := 0
+		start := time.Now()
+		err := r.Do(func() error {
+			jump(clock, count)
+			count++
+			time.Sleep(10 * time.Millisecond)
+			return errors.New("fail")
+		})
+		assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.GreaterOrEqual(t, count, 2)
+
+		// jumping an hour ahead does not use the limit up either.
+		count = 0
+		err = r.WithMaxAttempts(3).Do(func() error {
+			clock.Sleep(time.Hour)
+			count++
+			return errors.New("fail")
+		})
+		assert.NotErrorIs(t, err, retry.ErrDeadlineExceeded)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("elapsed", func(t *testing.T) {
+		// the system clock is read with its monotonic reading, which a wall clock jump leaves alone.
+		r := retry.New(func(error) bool { return true }, 3, 5, 5).WithNoJitter()
+		start := time.Now()
+		err := r.Do(func() error { return errors.New("fail") })
+		var exceeded *retry.ErrMaxAttemptExceeded
+		assert.ErrorAs(t, err, &exceeded)
+		assert.GreaterOrEqual(t, exceeded.Elapsed, 10*time.Millisecond)
+		assert.LessOrEqual(t, exceeded.Elapsed, time.Since(start))
+	})
+}
+
+func TestMaxAttemptExceededErrors(t *testing.T) {
+	var timeout *net.DNSError
+	errs := []error{&net.DNSError{Err: "timeout", IsTimeout: true}, io.ErrUnexpectedEOF, errors.New("503")}
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		return errs[count-1]
+	})
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, errs, exceeded.Errors())
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.ErrorAs(t, err, &timeout)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, "exceed max retry attempts. Original error: 503", fmt.Sprint(err))
+	assert.Equal(t, "exceed max retry attempts. Original error: 503\n\tattempt 1: lookup : timeout\n\tattempt 2: unexpected EOF\n\tattempt 3: 503", fmt.Sprintf("%+v", err))
+
+	err = r.WithHistoryLimit(1).Do(func() error { return io.ErrUnexpectedEOF })
+	assert.Equal(t, "exceed max retry attempts. Original error: unexpected EOF\n\tattempt 1: unexpected EOF\n\t... 1 attempts dropped\n\tattempt 3: unexpected EOF", fmt.Sprintf("%+v", err))
+}
+
+func TestMaxAttemptExceededMetadata(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 4, 5, 5).WithNoJitter()
+	start := time.Now()
+	err := r.Do(func() error {
+		time.Sleep(time.Millisecond)
+		return errors.New("fail")
+	})
+	elapsed := time.Since(start)
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 4, exceeded.AttemptsMade)
+	assert.Equal(t, 15*time.Millisecond, exceeded.Slept)
+	assert.GreaterOrEqual(t, exceeded.Elapsed, 19*time.Millisecond)
+	assert.LessOrEqual(t, exceeded.Elapsed, elapsed)
+
+	// AttemptsMade counts the attempts dropped from the history too.
+	err = r.WithHistoryLimit(1).Do(func() error { return errors.New("fail") })
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 4, exceeded.AttemptsMade)
+	assert.Len(t, exceeded.Attempts, 2)
+}
@@ -0,0 +1,26 @@
+package test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithStats()
+	retry.PublishExpvar("retry_test_policy", r)
+	assert.NoError(t, r.Do(func() error { return nil }))
+
+	var published map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("retry_test_policy").String()), &published))
+	assert.Equal(t, 1.0, published["Calls"])
+	assert.Equal(t, 1.0, published["Successes"])
+	assert.Equal(t, 1.0, published["AvgAttempts"])
+
+	assert.Panics(t, func() {
+		retry.PublishExpvar("retry_test_nostats", retry.New(func(error) bool { return true }, 3, 0, 0))
+	})
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverOnExhaustion(t *testing.T) {
+	down := errors.New("region down")
+	r := retry.New(func(e error) bool { return e == down }, 2, 0, 0)
+	fo := retry.NewFailover(r, retry.AdvanceOnExhaustion, "us-east", "us-west", "eu")
+
+	var tried []string
+	err := fo.DoContext(context.Background(), func(ctx context.Context, region string) error {
+		tried = append(tried, region)
+		if region == "eu" {
+			return nil
+		}
+		return down
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-east", "us-east", "us-west", "us-west", "eu"}, tried)
+
+	tried = nil
+	err = fo.DoContext(context.Background(), func(ctx context.Context, region string) error {
+		tried = append(tried, region)
+		return down
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Len(t, tried, 6)
+
+	// errors which are not retried stop the failover.
+	tried = nil
+	fatal := errors.New("bad request")
+	err = fo.DoContext(context.Background(), func(ctx context.Context, region string) error {
+		tried = append(tried, region)
+		return fatal
+	})
+	assert.Equal(t, fatal, err)
+	assert.Equal(t, []string{"us-east"}, tried)
+}
+
+func TestFailoverPerAttempt(t *testing.T) {
+	down := errors.New("region down")
+	r := retry.New(func(e error) bool { return e == down }, 2, 0, 0)
+	fo := retry.NewFailover(r, retry.AdvancePerAttempt, "us-east", "us-west", "eu")
+
+	var tried []string
+	err := fo.DoContext(context.Background(), func(ctx context.Context, region string) error {
+		tried = append(tried, region)
+		return down
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, []string{"us-east", "us-west", "eu", "us-east", "us-west", "eu"}, tried)
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Len(t, exceeded.Attempts, 6)
+
+	tried = nil
+	err = fo.DoContext(context.Background(), func(ctx context.Context, region string) error {
+		tried = append(tried, region)
+		if len(tried) == 2 {
+			return nil
+		}
+		return down
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-east", "us-west"}, tried)
+}
+
+func TestFailoverPerAttemptClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := retry.New(func(error) bool { return true }, 2, 1000, 1000).WithNoJitter().WithClock(clock)
+	fo := retry.NewFailover(r, retry.AdvancePerAttempt, "a", "b")
+
+	start := time.Now()
+	err := fo.DoContext(context.Background(), func(ctx context.Context, endpoint string) error {
+		clock.Sleep(time.Second)
+		return errors.New("down")
+	})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, clock.waited)
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 6*time.Second, exceeded.Elapsed)
+	assert.Equal(t, 2*time.Second, exceeded.Slept)
+	for _, a := range exceeded.Attempts {
+		assert.Equal(t, time.Second, a.Duration)
+	}
+}
+
+func TestWeightedFailover(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 1, 0, 0).WithRandSource(rand.NewSource(1))
+	fo := retry.NewWeightedFailover(r, retry.AdvanceOnExhaustion, []string{"a", "b", "c"}, []int{8, 1, 1})
+
+	first := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		var tried []string
+		_ = fo.DoContext(context.Background(), func(ctx context.Context, endpoint string) error {
+			tried = append(tried, endpoint)
+			return errors.New("down")
+		})
+		assert.ElementsMatch(t, []string{"a", "b", "c"}, tried)
+		first[tried[0]]++
+	}
+	assert.Greater(t, first["a"], 700)
+	assert.Greater(t, first["b"], 50)
+	assert.Greater(t, first["c"], 50)
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuture(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	count := 0
+	fut := retry.Go(context.Background(), r, func(ctx context.Context) (int, error) {
+		count = count + 1
+		if count < 2 {
+			return 0, errors.New("fail")
+		}
+		return 42, nil
+	})
+	<-fut.Done()
+	v, err := fut.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+	v, err = fut.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+	fut.Cancel()
+}
+
+func TestFutureCancel(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	started := make(chan struct{})
+	fut := retry.Go(context.Background(), r, func(ctx context.Context) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	<-started
+	fut.Cancel()
+	select {
+	case <-fut.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the attempt in flight was not canceled")
+	}
+	_, err := fut.Wait()
+	assert.ErrorIs(t, err, retry.ErrCanceled)
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureRateGate(t *testing.T) {
+	g := retry.NewFailureRateGate(time.Second, 0.5, 4, 50*time.Millisecond)
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithFailureRateGate(g)
+
+	count := 0
+	fail := func() error {
+		count = count + 1
+		return errors.New("fail")
+	}
+	// 3 failures in a row are below minSamples.
+	err := r.Do(fail)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+
+	// the 4th failure trips the gate, so no retry.
+	count = 0
+	err = r.Do(fail)
+	assert.ErrorIs(t, err, retry.ErrRetrySuspended)
+	assert.Equal(t, 1, count)
+	assert.False(t, g.Allow())
+	rate, samples := g.Rate()
+	assert.Equal(t, 1.0, rate)
+	assert.Equal(t, 4, samples)
+
+	// successes still go through while suspended.
+	assert.NoError(t, r.Do(func() error { return nil }))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, g.Allow())
+}
+
+func TestFailureRateGateWindow(t *testing.T) {
+	g := retry.NewFailureRateGate(50*time.Millisecond, 0.5, 1, time.Hour)
+	g.Record(false)
+	g.Record(false)
+	g.Record(true)
+	assert.True(t, g.Allow())
+	_, samples := g.Rate()
+	assert.Equal(t, 3, samples)
+
+	time.Sleep(60 * time.Millisecond)
+	_, samples = g.Rate()
+	assert.Equal(t, 0, samples)
+}
@@ -1,14 +1,65 @@
 module github.com/bluexlab/retry-go/test
 
-go 1.20
+go 1.21
 
 require (
-	github.com/bluexlab/retry-go v0.0.2
-	github.com/stretchr/testify v1.8.3
+	github.com/alicebob/miniredis/v2 v2.33.0
+	github.com/aws/aws-sdk-go-v2 v1.24.1
+	github.com/aws/smithy-go v1.19.0
+	github.com/bluexlab/retry-go v0.1.0
+	github.com/bluexlab/retry-go/idempotency/redisstore v0.0.0
+	github.com/bluexlab/retry-go/retryawsv2 v0.0.0
+	github.com/bluexlab/retry-go/retrybackoff v0.0.0
+	github.com/bluexlab/retry-go/retrygrpc v0.0.0
+	github.com/bluexlab/retry-go/retryk8s v0.0.0
+	github.com/bluexlab/retry-go/retrylogrus v0.0.0
+	github.com/bluexlab/retry-go/retryzap v0.0.0
+	github.com/cenkalti/backoff/v4 v4.3.0
+	github.com/jackc/pgx/v5 v5.5.5
+	github.com/lib/pq v1.10.9
+	github.com/redis/go-redis/v9 v9.7.0
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.8.4
+	go.uber.org/zap v1.27.0
+	golang.org/x/time v0.5.0
+	google.golang.org/grpc v1.64.1
+	gopkg.in/yaml.v3 v3.0.1
+	k8s.io/apimachinery v0.29.3
 )
 
 require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-logr/logr v1.3.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/crypto v0.24.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	k8s.io/klog/v2 v2.110.1 // indirect
+	k8s.io/utils v0.0.0-20230726121419-3b25d923346b // indirect
 )
+
+replace github.com/bluexlab/retry-go => ../
+
+replace github.com/bluexlab/retry-go/idempotency/redisstore => ../idempotency/redisstore
+
+replace github.com/bluexlab/retry-go/retryawsv2 => ../retryawsv2
+
+replace github.com/bluexlab/retry-go/retrybackoff => ../retrybackoff
+
+replace github.com/bluexlab/retry-go/retrygrpc => ../retrygrpc
+
+replace github.com/bluexlab/retry-go/retryk8s => ../retryk8s
+
+replace github.com/bluexlab/retry-go/retrylogrus => ../retrylogrus
+
+replace github.com/bluexlab/retry-go/retryzap => ../retryzap
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retrygrpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream replies the messages in replies, then fails with err.
+type fakeStream struct {
+	grpc.ClientStream
+	sent    []any
+	closed  bool
+	replies []string
+	err     error
+}
+
+func (s *fakeStream) SendMsg(m any) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func (s *fakeStream) CloseSend() error {
+	s.closed = true
+	return nil
+}
+
+func (s *fakeStream) RecvMsg(m any) error {
+	if len(s.replies) == 0 {
+		return s.err
+	}
+	*m.(*string) = s.replies[0]
+	s.replies = s.replies[1:]
+	return nil
+}
+
+func retryUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	policy := retry.New(retryUnavailable, 3, 0, 0)
+	desc := &grpc.StreamDesc{ServerStreams: true}
+
+	opened := 0
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		opened++
+		if opened < 3 {
+			return nil, unavailable
+		}
+		return &fakeStream{replies: []string{"a"}, err: io.EOF}, nil
+	}
+	stream, err := retrygrpc.StreamClientInterceptor(policy, false)(context.Background(), desc, nil, "/svc/List", streamer)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, opened)
+	var reply string
+	assert.NoError(t, stream.RecvMsg(&reply))
+	assert.Equal(t, "a", reply)
+	assert.Equal(t, io.EOF, stream.RecvMsg(&reply))
+
+	// the error of the last attempt returns once the policy gives up.
+	opened = -10
+	_, err = retrygrpc.StreamClientInterceptor(policy, false)(context.Background(), desc, nil, "/svc/List", streamer)
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, -7, opened)
+}
+
+func TestStreamClientInterceptorReestablish(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "stream reset")
+	policy := retry.New(retryUnavailable, 3, 0, 0)
+	desc := &grpc.StreamDesc{ServerStreams: true}
+
+	var streams []*fakeStream
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		s := &fakeStream{err: unavailable}
+		if len(streams) == 2 {
+			s.replies = []string{"a", "b"}
+		}
+		streams = append(streams, s)
+		return s, nil
+	}
+	stream, err := retrygrpc.StreamClientInterceptor(policy, true)(context.Background(), desc, nil, "/svc/List", streamer)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.SendMsg("request"))
+	assert.NoError(t, stream.CloseSend())
+
+	var reply string
+	assert.NoError(t, stream.RecvMsg(&reply))
+	assert.Equal(t, "a", reply)
+	assert.Len(t, streams, 3)
+	for _, s := range streams {
+		assert.Equal(t, []any{"request"}, s.sent)
+		assert.True(t, s.closed)
+	}
+
+	// once a message is received, a broken stream is not reopened.
+	assert.NoError(t, stream.RecvMsg(&reply))
+	assert.Equal(t, unavailable, stream.RecvMsg(&reply))
+	assert.Len(t, streams, 3)
+
+	// bidirectional streams are not reopened.
+	streams = nil
+	stream, err = retrygrpc.StreamClientInterceptor(policy, true)(context.Background(), &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, nil, "/svc/Chat", streamer)
+	assert.NoError(t, err)
+	assert.Equal(t, unavailable, stream.RecvMsg(&reply))
+	assert.Len(t, streams, 1)
+}
+
+func TestRetryOnCodes(t *testing.T) {
+	shouldRetry := retrygrpc.RetryOnCodes(codes.Unavailable, codes.ResourceExhausted)
+	assert.True(t, shouldRetry(status.Error(codes.Unavailable, "down")))
+	assert.True(t, shouldRetry(fmt.Errorf("list orders: %w", status.Error(codes.ResourceExhausted, "quota"))))
+	assert.False(t, shouldRetry(status.Error(codes.InvalidArgument, "bad")))
+	assert.False(t, shouldRetry(errors.New("plain")))
+	assert.False(t, shouldRetry(nil))
+
+	count := 0
+	err := retry.New(shouldRetry, 3, 0, 0).Do(func() error {
+		count = count + 1
+		return status.Error(codes.Unavailable, "down")
+	})
+	assert.Equal(t, 3, count)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
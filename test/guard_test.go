@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardConcurrency(t *testing.T) {
+	g := retry.NewGuard(1, 0, false)
+	retry.SetGlobalGuard(g)
+	defer retry.SetGlobalGuard(nil)
+	assert.Equal(t, g, retry.GlobalGuard())
+
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	retrying := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		count := 0
+		_ = r.Do(func() error {
+			count = count + 1
+			if count == 2 {
+				close(retrying)
+				<-release
+			}
+			return errors.New("fail")
+		})
+	}()
+	<-retrying
+	assert.Equal(t, 1, g.Stats().Active)
+
+	// the only slot is taken, so the second operation may not retry.
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrRetryRejected)
+	assert.Equal(t, 1, count)
+	// first attempts are never held back.
+	assert.NoError(t, r.Do(func() error { return nil }))
+
+	close(release)
+	wg.Wait()
+	stats := g.Stats()
+	assert.Equal(t, 0, stats.Active)
+	assert.Equal(t, uint64(1), stats.Rejected)
+	assert.Equal(t, uint64(2), stats.Admitted)
+}
+
+func TestGuardRate(t *testing.T) {
+	g := retry.NewGuard(0, 200, true)
+	retry.SetGlobalGuard(g)
+	defer retry.SetGlobalGuard(nil)
+
+	r := retry.New(func(error) bool { return true }, 301, 0, 0)
+	start := time.Now()
+	_ = r.Do(func() error { return errors.New("fail") })
+	// a burst of 200, then 100 retries at 200 per second.
+	assert.GreaterOrEqual(t, time.Since(start), 450*time.Millisecond)
+	assert.Equal(t, uint64(300), g.Stats().Admitted)
+	// tokens trickling in during the burst may spare a few retries the wait.
+	assert.InDelta(t, 100, g.Stats().Queued, 10)
+
+	retry.SetGlobalGuard(retry.NewGuard(0, 1, false))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	err := r.DoContext(ctx, func(context.Context) error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrRetryRejected)
+	assert.Equal(t, 2, count)
+}
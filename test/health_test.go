@@ -0,0 +1,87 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProber(t *testing.T) {
+	var down atomic.Bool
+	var probes atomic.Int32
+	probe := func(context.Context) error {
+		probes.Add(1)
+		if down.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	var mu sync.Mutex
+	var transitions []health.Transition
+	policy := retry.New(func(error) bool { return true }, 3, 1, 5)
+	p := health.NewProber(probe, policy, 5*time.Millisecond, func(tr health.Transition) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, tr)
+	})
+	status, _ := p.Status()
+	assert.Equal(t, health.Unknown, status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(ctx)
+	}()
+
+	waitFor := func(want health.Status) {
+		assert.Eventually(t, func() bool {
+			status, _ := p.Status()
+			return status == want
+		}, time.Second, time.Millisecond)
+	}
+	waitFor(health.Healthy)
+	down.Store(true)
+	waitFor(health.Unhealthy)
+	_, err := p.Status()
+	assert.EqualError(t, err, "connection refused")
+	down.Store(false)
+	waitFor(health.Healthy)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	var to []health.Status
+	for _, tr := range transitions {
+		to = append(to, tr.To)
+	}
+	assert.Equal(t, []health.Status{health.Healthy, health.Unhealthy, health.Healthy}, to)
+	assert.Error(t, transitions[1].Err)
+}
+
+func TestProberCheck(t *testing.T) {
+	p := health.NewProber(func(context.Context) error { return errors.New("down") }, retry.New(func(error) bool { return true }, 1, 1, 1), time.Hour, nil)
+	assert.True(t, p.Check(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go p.Run(ctx)
+	defer cancel()
+	assert.Eventually(t, func() bool { return !p.Check(context.Background()) }, time.Second, time.Millisecond)
+
+	count := 0
+	err := retry.New(func(error) bool { return true }, 5, 0, 0).WithHealthCheck(p.Check).Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrDependencyDown)
+	assert.Equal(t, 1, count)
+}
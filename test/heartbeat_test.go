@@ -0,0 +1,56 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeat(t *testing.T) {
+	var failing atomic.Bool
+	var sent atomic.Int32
+	var lost, recovered atomic.Int32
+	send := func(context.Context) error {
+		sent.Add(1)
+		if failing.Load() {
+			return errors.New("lease expired")
+		}
+		return nil
+	}
+	policy := retry.New(func(error) bool { return true }, 2, 1, 2)
+	h := health.NewHeartbeat(send, policy, 2*time.Millisecond, func(tr health.Transition) {
+		switch tr.To {
+		case health.Unhealthy:
+			lost.Add(1)
+		case health.Healthy:
+			recovered.Add(1)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Run(ctx)
+	}()
+
+	assert.Eventually(t, h.Alive, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return sent.Load() > 3 }, time.Second, time.Millisecond)
+	failing.Store(true)
+	assert.Eventually(t, func() bool { return !h.Alive() }, time.Second, time.Millisecond)
+	assert.EqualError(t, h.Err(), "lease expired")
+	failing.Store(false)
+	assert.Eventually(t, h.Alive, time.Second, time.Millisecond)
+	assert.NoError(t, h.Err())
+	cancel()
+	<-done
+
+	assert.Equal(t, int32(1), lost.Load())
+	assert.Equal(t, int32(2), recovered.Load())
+}
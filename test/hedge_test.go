@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	var launched atomic.Int32
+	canceled := make(chan struct{})
+	start := time.Now()
+	err := retry.Hedge(context.Background(), r, 20*time.Millisecond, func(ctx context.Context) error {
+		if launched.Add(1) == 1 {
+			// the first attempt hangs until canceled.
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), launched.Load())
+	assert.Less(t, time.Since(start), time.Second)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the slow attempt was not canceled")
+	}
+}
+
+func TestHedgeExhausted(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 1, 1)
+	var launched atomic.Int32
+	err := retry.Hedge(context.Background(), r, time.Hour, func(ctx context.Context) error {
+		launched.Add(1)
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, int32(3), launched.Load())
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Len(t, exceeded.Attempts, 3)
+	assert.Equal(t, 3, exceeded.AttemptsMade)
+}
+
+func TestHedgeNotRetryable(t *testing.T) {
+	r := retry.New(func(error) bool { return false }, 3, 0, 0)
+	fatal := errors.New("fatal")
+	err := retry.Hedge(context.Background(), r, time.Hour, func(ctx context.Context) error {
+		return fatal
+	})
+	assert.Equal(t, fatal, err)
+}
+
+func TestHedgeAborted(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	err := retry.Hedge(ctx, r, 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type throttled struct {
+	after time.Duration
+}
+
+func (e *throttled) Error() string {
+	return "429 too many requests"
+}
+
+func (e *throttled) RetryAfter() time.Duration {
+	return e.after
+}
+
+func TestDelayHint(t *testing.T) {
+	var delays []time.Duration
+	r := retry.New(func(e error) bool { return true }, 4, 1, 1).WithOnRetry(func(attempt int, err error, nextDelay time.Duration) {
+		delays = append(delays, nextDelay)
+	})
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		switch count {
+		case 1:
+			return &throttled{after: 2 * time.Millisecond}
+		case 2:
+			return fmt.Errorf("call: %w", &throttled{after: time.Millisecond})
+		case 3:
+			// no hint, but for the computed delay.
+			return &throttled{}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, delays, 3)
+	assert.Equal(t, 2*time.Millisecond, delays[0])
+	assert.Equal(t, time.Millisecond, delays[1])
+	assert.LessOrEqual(t, delays[2], time.Millisecond)
+
+	advice := retry.NewAdvisor(r).Advise(errors.Join(errors.New("other"), &throttled{after: time.Minute}))
+	assert.Equal(t, time.Minute, advice.Delay)
+}
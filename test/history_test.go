@@ -0,0 +1,66 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryLimit(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 20, 0, 0).WithHistoryLimit(3)
+
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		return &attemptNo{count}
+	})
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 14, exceeded.Dropped)
+	var kept []int
+	for _, a := range exceeded.Attempts {
+		assert.Equal(t, a.Attempt, a.Err.(*attemptNo).n)
+		kept = append(kept, a.Attempt)
+	}
+	assert.Equal(t, []int{1, 2, 3, 18, 19, 20}, kept)
+
+	// the default limit keeps short histories intact.
+	err = retry.New(func(error) bool { return true }, 20, 0, 0).Do(func() error { return &attemptNo{} })
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Len(t, exceeded.Attempts, 20)
+	assert.Zero(t, exceeded.Dropped)
+
+	err = retry.New(func(error) bool { return true }, 200, 0, 0).WithHistoryLimit(0).Do(func() error { return &attemptNo{} })
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Len(t, exceeded.Attempts, 200)
+}
+
+type attemptNo struct {
+	n int
+}
+
+func (e *attemptNo) Error() string {
+	return "failed"
+}
+
+func TestHistoryIsRecycled(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random with the race detector")
+	}
+	needRetry := errors.New("ALSKDJFALKDSJF")
+	r := retry.New(func(error) bool { return true }, 5, 0, 0)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		count := 0
+		_ = r.Do(func() error {
+			count = count + 1
+			if count < 3 {
+				return needRetry
+			}
+			return nil
+		})
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/idempotency"
+	"github.com/bluexlab/retry-go/idempotency/redisstore"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKey(t *testing.T) {
+	key := idempotency.NewKey()
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, key)
+	assert.NotEqual(t, key, idempotency.NewKey())
+
+	ctx := idempotency.WithKey(context.Background(), key)
+	got, ok := idempotency.KeyFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, key, got)
+	_, ok = idempotency.KeyFrom(context.Background())
+	assert.False(t, ok)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, idempotency.NewMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	testStore(t, redisstore.New(client, "idem:"))
+}
+
+func testStore(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	key := idempotency.NewKey()
+
+	ok, err := store.Reserve(ctx, key, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.Reserve(ctx, key, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	done, err := store.Done(ctx, key)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.NoError(t, store.Complete(ctx, key))
+	done, err = store.Done(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	assert.NoError(t, store.Release(ctx, key))
+	ok, err = store.Reserve(ctx, key, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// completing an unknown key does not create it.
+	other := idempotency.NewKey()
+	assert.NoError(t, store.Complete(ctx, other))
+	done, err = store.Done(ctx, other)
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	// the guard skips the retry of a write which went through.
+	count := 0
+	write := idempotency.NewKey()
+	_, _ = store.Reserve(ctx, write, time.Minute)
+	r := retry.New(func(error) bool { return true }, 5, 0, 0).WithIdempotencyGuard(idempotency.Guard(store, write))
+	err = r.Do(func() error {
+		count = count + 1
+		assert.NoError(t, store.Complete(ctx, write))
+		return errors.New("response lost")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
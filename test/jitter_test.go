@@ -0,0 +1,74 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func jitterSamples(r retry.Retry, n int) (min, max, mean time.Duration) {
+	const samples = 2000
+	min = time.Duration(1<<63 - 1)
+	var sum time.Duration
+	for i := 0; i < samples; i++ {
+		d := r.Delay(n)
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+		sum += d
+	}
+	return min, max, sum / samples
+}
+
+func TestJitter(t *testing.T) {
+	r := retry.NewWithDurations(func(e error) bool { return true }, 10, 100*time.Millisecond, time.Second).WithRandSource(rand.NewSource(1))
+
+	// full: uniform in [0, 400ms].
+	min, max, mean := jitterSamples(r, 3)
+	assert.GreaterOrEqual(t, min, time.Duration(0))
+	assert.Less(t, min, 20*time.Millisecond)
+	assert.LessOrEqual(t, max, 400*time.Millisecond)
+	assert.InDelta(t, 200*time.Millisecond, mean, float64(20*time.Millisecond))
+
+	// equal: uniform in [200ms, 400ms].
+	min, max, mean = jitterSamples(r.WithJitter(retry.EqualJitter), 3)
+	assert.GreaterOrEqual(t, min, 200*time.Millisecond)
+	assert.LessOrEqual(t, max, 400*time.Millisecond)
+	assert.InDelta(t, 300*time.Millisecond, mean, float64(20*time.Millisecond))
+
+	// decorrelated: uniform in [100ms, 3 * 400ms capped to 1s].
+	min, max, mean = jitterSamples(r.WithJitter(retry.DecorrelatedJitter), 4)
+	assert.GreaterOrEqual(t, min, 100*time.Millisecond)
+	assert.LessOrEqual(t, max, time.Second)
+	assert.InDelta(t, 550*time.Millisecond, mean, float64(40*time.Millisecond))
+
+	min, max, _ = jitterSamples(r.WithJitter(retry.NoJitter), 3)
+	assert.Equal(t, 400*time.Millisecond, min)
+	assert.Equal(t, 400*time.Millisecond, max)
+
+	assert.Equal(t, "decorrelated", retry.DecorrelatedJitter.String())
+}
+
+func TestDecorrelatedJitterDo(t *testing.T) {
+	var delays []time.Duration
+	r := retry.NewWithDurations(func(e error) bool { return true }, 6, time.Millisecond, 5*time.Millisecond).WithJitter(retry.DecorrelatedJitter)
+	err := r.Do(func() error { return assert.AnError })
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	for _, a := range exceeded.Attempts[:5] {
+		delays = append(delays, a.Delay)
+	}
+	prev := time.Millisecond
+	for _, d := range delays {
+		assert.GreaterOrEqual(t, d, time.Millisecond)
+		assert.LessOrEqual(t, d, 3*prev)
+		assert.LessOrEqual(t, d, 5*time.Millisecond)
+		prev = d
+	}
+}
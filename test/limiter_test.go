@@ -0,0 +1,40 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestWithLimiter(t *testing.T) {
+	l := rate.NewLimiter(rate.Every(20*time.Millisecond), 1)
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithLimiter(l)
+
+	count := 0
+	start := time.Now()
+	err := r.Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+	// the burst lets the first attempt through, the others wait for a token.
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+
+	// the first attempt of a call waits too.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	count = 0
+	err = retry.New(func(error) bool { return true }, 3, 0, 0).WithLimiter(rate.NewLimiter(rate.Every(time.Hour), 0)).DoContext(ctx, func(ctx context.Context) error {
+		count = count + 1
+		return nil
+	})
+	var aborted *retry.ErrAborted
+	assert.ErrorAs(t, err, &aborted)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retrylogrus"
+	"github.com/bluexlab/retry-go/retryzap"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type recordingLogger struct {
+	records []string
+}
+
+func (l *recordingLogger) Debug(_ context.Context, msg string, keyvals ...any) {
+	l.records = append(l.records, "debug "+msg)
+}
+
+func (l *recordingLogger) Warn(_ context.Context, msg string, keyvals ...any) {
+	l.records = append(l.records, "warn "+msg)
+}
+
+func TestWithCustomLogger(t *testing.T) {
+	l := &recordingLogger{}
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithCustomLogger(l)
+	assert.Error(t, r.Do(func() error { return errors.New("boom") }))
+	assert.Equal(t, []string{"debug retrying", "debug retrying", "warn retry attempts exhausted"}, l.records)
+}
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	r := retry.New(func(error) bool { return true }, 2, 0, 0).WithCustomLogger(retryzap.New(zap.New(core)))
+	boom := errors.New("boom")
+	assert.Error(t, r.Do(func() error { return boom }))
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	assert.Equal(t, "retrying", entries[0].Message)
+	assert.Equal(t, map[string]any{"attempt": int64(1), "error": "boom", "delay": time.Duration(0)}, entries[0].ContextMap())
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, "retry attempts exhausted", entries[1].Message)
+	assert.Equal(t, int64(2), entries[1].ContextMap()["attempts"])
+}
+
+func TestLogrusLogger(t *testing.T) {
+	l, hook := logrustest.NewNullLogger()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.DebugLevel)
+	r := retry.New(func(error) bool { return true }, 2, 0, 0).WithCustomLogger(retrylogrus.New(l))
+	boom := errors.New("boom")
+	assert.Error(t, r.Do(func() error { return boom }))
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, logrus.DebugLevel, entries[0].Level)
+	assert.Equal(t, "retrying", entries[0].Message)
+	assert.Equal(t, logrus.Fields{"attempt": 1, "error": boom, "delay": time.Duration(0)}, entries[0].Data)
+	assert.Equal(t, logrus.WarnLevel, entries[1].Level)
+	assert.Equal(t, 2, entries[1].Data["attempts"])
+}
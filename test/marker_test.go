@@ -0,0 +1,76 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnrecoverable(t *testing.T) {
+	conflict := errors.New("409 conflict")
+	r := retry.New(func(e error) bool { return true }, 5, 0, 0)
+
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		if count == 2 {
+			return retry.Unrecoverable(conflict)
+		}
+		return conflict
+	})
+	assert.Equal(t, 2, count)
+	assert.Equal(t, conflict, err)
+
+	// the mark is found when wrapped too.
+	count = 0
+	err = r.Do(func() error {
+		count = count + 1
+		return fmt.Errorf("update: %w", retry.Unrecoverable(conflict))
+	})
+	assert.Equal(t, 1, count)
+	assert.ErrorIs(t, err, conflict)
+	assert.True(t, retry.IsUnrecoverable(err))
+	assert.Equal(t, "update: 409 conflict", err.Error())
+
+	assert.NoError(t, retry.Unrecoverable(nil))
+	assert.False(t, retry.IsUnrecoverable(conflict))
+}
+
+func TestMarkRetryable(t *testing.T) {
+	eof := errors.New("EOF")
+	r := retry.New(func(e error) bool { return false }, 3, 0, 0)
+
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		if count == 1 {
+			return retry.MarkRetryable(eof)
+		}
+		return eof
+	})
+	assert.Equal(t, 2, count)
+	assert.Equal(t, eof, err)
+
+	count = 0
+	err = r.Do(func() error {
+		count = count + 1
+		return fmt.Errorf("read: %w", retry.MarkRetryable(eof))
+	})
+	assert.Equal(t, 3, count)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, eof)
+	assert.True(t, retry.IsMarkedRetryable(err))
+
+	// Unrecoverable wins.
+	count = 0
+	err = r.Do(func() error {
+		count = count + 1
+		return retry.MarkRetryable(retry.Unrecoverable(eof))
+	})
+	assert.Equal(t, 1, count)
+	assert.ErrorIs(t, err, eof)
+	assert.NoError(t, retry.MarkRetryable(nil))
+}
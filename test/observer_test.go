@@ -0,0 +1,45 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithObserver(t *testing.T) {
+	var events []retry.Event
+	observer := retry.ObserverFunc(func(ctx context.Context, e retry.Event) {
+		e.Duration = 0
+		events = append(events, e)
+	})
+	r := retry.New(func(error) bool { return true }, 2, 1, 1).WithNoJitter().WithObserver(observer)
+
+	boom := errors.New("boom")
+	count := 0
+	assert.NoError(t, r.Do(func() error {
+		count = count + 1
+		if count == 1 {
+			return boom
+		}
+		return nil
+	}))
+	assert.Equal(t, []retry.Event{
+		{Kind: retry.AttemptStarted, Attempt: 1},
+		{Kind: retry.AttemptFailed, Attempt: 1, Err: boom},
+		{Kind: retry.Sleeping, Attempt: 1, Delay: retry.New(nil, 1, 1, 1).WithNoJitter().Delay(1)},
+		{Kind: retry.AttemptStarted, Attempt: 2},
+		{Kind: retry.Succeeded, Attempt: 2},
+	}, events)
+
+	events = nil
+	assert.Error(t, r.Do(func() error { return boom }))
+	kinds := []string{}
+	for _, e := range events {
+		kinds = append(kinds, e.Kind.String())
+	}
+	assert.Equal(t, []string{"attempt started", "attempt failed", "sleeping", "attempt started", "attempt failed", "exhausted"}, kinds)
+	assert.Equal(t, retry.Event{Kind: retry.Exhausted, Attempt: 2, Err: boom}, events[len(events)-1])
+}
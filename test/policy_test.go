@@ -0,0 +1,83 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPolicyJSON(t *testing.T) {
+	p := retry.Policy{
+		MaxAttempts:    5,
+		InitialDelay:   100 * time.Millisecond,
+		MaxDelay:       5 * time.Second,
+		Backoff:        retry.BackoffLinear,
+		Step:           time.Second,
+		Jitter:         retry.EqualJitter,
+		AttemptTimeout: 2 * time.Second,
+	}
+	data, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"maxAttempts":5,"initialDelay":"100ms","maxDelay":"5s","backoff":"linear","step":"1s","jitter":"equal","attemptTimeout":"2s"}`, string(data))
+
+	var decoded retry.Policy
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, p, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"maxDelay":"5 seconds"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"jitter":"some"}`), &decoded))
+}
+
+func TestPolicyYAML(t *testing.T) {
+	var p retry.Policy
+	err := yaml.Unmarshal([]byte("maxAttempts: 3\ninitialDelay: 10ms\nmaxDelay: 1s\njitter: none\n"), &p)
+	assert.NoError(t, err)
+	assert.Equal(t, retry.Policy{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, MaxDelay: time.Second, Jitter: retry.NoJitter}, p)
+}
+
+func TestFromConfig(t *testing.T) {
+	always := func(error) bool { return true }
+	r, err := retry.FromConfig(retry.Policy{MaxAttempts: 4, InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: retry.NoJitter}, always)
+	assert.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, r.Delay(1))
+	assert.Equal(t, 400*time.Millisecond, r.Delay(3))
+	assert.Equal(t, time.Second, r.Delay(5))
+
+	r, err = retry.FromConfig(retry.Policy{MaxAttempts: 4, InitialDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond, Backoff: retry.BackoffLinear, Step: 100 * time.Millisecond, Jitter: retry.NoJitter}, always)
+	assert.NoError(t, err)
+	assert.Equal(t, 200*time.Millisecond, r.Delay(2))
+	assert.Equal(t, 250*time.Millisecond, r.Delay(3))
+
+	r, err = retry.FromConfig(retry.Policy{MaxAttempts: 4, InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Backoff: retry.BackoffConstant, Jitter: retry.NoJitter}, always)
+	assert.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, r.Delay(3))
+
+	var invalid *retry.ErrInvalidConfig
+	_, err = retry.FromConfig(retry.Policy{}, always)
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "maxAttempts", invalid.Param)
+	_, err = retry.FromConfig(retry.Policy{MaxAttempts: 1, InitialDelay: time.Second}, always)
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "initialDelay", invalid.Param)
+	_, err = retry.FromConfig(retry.Policy{MaxAttempts: 1, Backoff: "fibonacci"}, always)
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "backoff", invalid.Param)
+	_, err = retry.FromConfig(retry.Policy{MaxAttempts: 1}, nil)
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "shouldRetry", invalid.Param)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, retry.Policy{MaxAttempts: 3, MaxDelay: time.Second}.Validate())
+	assert.NoError(t, retry.Policy{Unlimited: true}.Validate())
+
+	var invalid *retry.ErrInvalidConfig
+	assert.ErrorAs(t, retry.Policy{MaxAttempts: 3, Step: -time.Second}.Validate(), &invalid)
+	assert.Equal(t, "step", invalid.Param)
+	assert.ErrorAs(t, retry.Policy{MaxAttempts: 3, Jitter: retry.Jitter(9)}.Validate(), &invalid)
+	assert.Equal(t, "jitter", invalid.Param)
+}
@@ -0,0 +1,29 @@
+package test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/bluexlab/retry-go/retrysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresShouldRetry(t *testing.T) {
+	assert.True(t, retrysql.PostgresShouldRetry(&pq.Error{Code: "40001"}))
+	assert.True(t, retrysql.PostgresShouldRetry(fmt.Errorf("transfer: %w", &pq.Error{Code: "40P01"})))
+	assert.True(t, retrysql.PostgresShouldRetry(&pgconn.PgError{Code: "57P03"}))
+	assert.True(t, retrysql.PostgresShouldRetry(&pgconn.PgError{Code: "08006"}))
+	assert.False(t, retrysql.PostgresShouldRetry(&pq.Error{Code: "23505"}))
+	assert.False(t, retrysql.PostgresShouldRetry(&pgconn.PgError{Code: "42P01"}))
+
+	assert.True(t, retrysql.PostgresShouldRetry(driver.ErrBadConn))
+	assert.True(t, retrysql.PostgresShouldRetry(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}))
+	assert.False(t, retrysql.PostgresShouldRetry(errors.New("sql: no rows in result set")))
+}
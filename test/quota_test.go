@@ -0,0 +1,57 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryQuota(t *testing.T) {
+	q := retry.NewRetryQuota(20)
+	r := retry.New(func(error) bool { return true }, 3, 0, 0).WithRetryQuota(q)
+
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		if count < 3 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	// 2 retries cost 10, the success gives back the cost of the last one.
+	assert.NoError(t, err)
+	assert.Equal(t, retry.QuotaStats{Available: 15, Capacity: 20}, q.Stats())
+
+	// a timeout costs more.
+	count = 0
+	err = r.Do(func() error {
+		count = count + 1
+		return fmt.Errorf("call: %w", context.DeadlineExceeded)
+	})
+	assert.ErrorIs(t, err, retry.ErrQuotaExhausted)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, retry.QuotaStats{Available: 5, Capacity: 20, Rejected: 1}, q.Stats())
+
+	// single attempts replenish one unit each, up to the capacity.
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, r.Do(func() error { return nil }))
+	}
+	assert.Equal(t, 20, q.Stats().Available)
+}
+
+func TestRetryQuotaCosts(t *testing.T) {
+	q := retry.NewRetryQuota(3).WithCosts(1, 3)
+	r := retry.New(func(error) bool { return true }, 10, 0, 0).WithRetryQuota(q)
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrQuotaExhausted)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, 0, q.Stats().Available)
+}
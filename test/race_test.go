@@ -0,0 +1,65 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaceDo(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	canceled := make(chan struct{})
+	err := retry.RaceDo(context.Background(), r,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		},
+		func(ctx context.Context) error {
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the losing alternative was not canceled")
+	}
+}
+
+func TestRaceDoRetriesRounds(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	var east, west atomic.Int32
+	err := retry.RaceDo(context.Background(), r,
+		func(ctx context.Context) error {
+			east.Add(1)
+			return errors.New("us-east down")
+		},
+		func(ctx context.Context) error {
+			if west.Add(1) < 3 {
+				return errors.New("eu-west down")
+			}
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), west.Load())
+	// the last round returns without waiting for us-east.
+	assert.GreaterOrEqual(t, east.Load(), int32(2))
+
+	// a round fails with an error the policy retries over one it does not.
+	fatal := errors.New("fatal")
+	transient := errors.New("transient")
+	r = retry.New(func(err error) bool { return err == transient }, 2, 0, 0)
+	err = retry.RaceDo(context.Background(), r,
+		func(ctx context.Context) error { return fatal },
+		func(ctx context.Context) error { return transient },
+	)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, transient)
+}
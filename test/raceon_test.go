@@ -0,0 +1,6 @@
+//go:build race
+
+package test
+
+// raceEnabled reports whether the race detector is on, which makes sync.Pool drop items at random.
+const raceEnabled = true
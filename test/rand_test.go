@@ -0,0 +1,73 @@
+package test
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRandSource(t *testing.T) {
+	src := &countingSource{Source: rand.NewSource(1)}
+	r := retry.New(func(error) bool { return true }, 4, 1, 2).WithRandSource(src)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = r.Do(func() error { return errors.New("fail") })
+		}()
+	}
+	wg.Wait()
+	// 3 delays for each of the 8 calls.
+	assert.Equal(t, 24, src.calls)
+}
+
+func TestWithRandSourceIsReproducible(t *testing.T) {
+	delays := func() []time.Duration {
+		r := retry.New(func(error) bool { return true }, 10, 100, 10000).WithRandSource(rand.NewSource(42))
+		var delays []time.Duration
+		for n := 1; n <= 10; n++ {
+			delays = append(delays, r.Delay(n))
+		}
+		return delays
+	}
+	first := delays()
+	assert.Equal(t, first, delays())
+	assert.NotEqual(t, first[1:], first[:9])
+}
+
+// countingSource is not safe for concurrent use on purpose; Retry must serialize the calls.
+type countingSource struct {
+	rand.Source
+	calls int
+}
+
+func (s *countingSource) Int63() int64 {
+	s.calls++
+	return s.Source.Int63()
+}
+
+func TestCryptoRandSource(t *testing.T) {
+	src := retry.CryptoRandSource()
+	seen := map[int64]bool{}
+	for i := 0; i < 100; i++ {
+		n := src.Int63()
+		assert.GreaterOrEqual(t, n, int64(0))
+		seen[n] = true
+	}
+	assert.Greater(t, len(seen), 90)
+
+	count := 0
+	err := retry.New(func(error) bool { return true }, 3, 1, 2).WithRandSource(src).Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+}
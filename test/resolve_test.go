@@ -0,0 +1,47 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoResolved(t *testing.T) {
+	pods := [][]string{
+		{"10.0.0.1:80"},
+		{"10.0.0.1:80", "10.0.0.2:80"},
+		{"10.0.0.3:80", "10.0.0.2:80", "10.0.0.4:80"},
+	}
+	resolves := 0
+	resolve := func(ctx context.Context, target string) ([]string, error) {
+		assert.Equal(t, "svc.local:80", target)
+		resolves++
+		return pods[resolves-1], nil
+	}
+
+	var dialed []string
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	err := r.DoResolved(context.Background(), "svc.local:80", resolve, func(ctx context.Context, addr string) error {
+		dialed = append(dialed, addr)
+		return errors.New("connection refused")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, resolves)
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.4:80"}, dialed)
+}
+
+func TestResolveDNS(t *testing.T) {
+	addrs, err := retry.ResolveDNS(context.Background(), "127.0.0.1:8080")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:8080"}, addrs)
+
+	addrs, err = retry.ResolveDNS(context.Background(), "localhost:8080")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, addrs)
+
+	_, err = retry.ResolveDNS(context.Background(), "no-port")
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func fetchWith(r retry.Retrier, f func() error) error {
+	return r.Do(f)
+}
+
+func TestNopRetrier(t *testing.T) {
+	fail := errors.New("fail")
+	count := 0
+	err := fetchWith(retry.NopRetrier{}, func() error {
+		count++
+		return fail
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 1, count)
+
+	err = retry.NopRetrier{}.DoWithAttempt(func(attempt int) error {
+		assert.Equal(t, 1, attempt)
+		return retry.Unrecoverable(fail)
+	})
+	assert.Equal(t, fail, err)
+
+	count = 0
+	err = fetchWith(retry.New(func(error) bool { return true }, 3, 0, 0), func() error {
+		count++
+		return fail
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+}
+
+func TestDoValueWith(t *testing.T) {
+	v, err := retry.DoValueWith(context.Background(), retry.NopRetrier{}, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	r := retry.WithRetryIfValue(retry.New(func(error) bool { return false }, 3, 0, 0), func(v int) bool { return v < 3 })
+	count := 0
+	v, err = retry.DoValueWith(context.Background(), r, func(ctx context.Context) (int, error) {
+		count++
+		return count, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+}
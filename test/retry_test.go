@@ -1,9 +1,12 @@
 package test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/bluexlab/retry-go"
 	"github.com/stretchr/testify/assert"
@@ -56,3 +59,634 @@ func TestRetry(t *testing.T) {
 	assert.Equal(t, 2, count)
 	assert.Equal(t, "hello world", result)
 }
+
+func TestDoContext(t *testing.T) {
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "request-1"))
+	r := retry.New(func(e error) bool { return true }, 10, 10000, 10000).WithNoJitter()
+
+	var values []any
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		values = append(values, ctx.Value(key{}))
+		return errors.New("fail")
+	})
+	// the cancellation interrupts the 10 seconds sleep.
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, retry.ErrCanceled)
+	assert.Equal(t, []any{"request-1"}, values)
+}
+
+func TestMaxDelaySmallerThanInitDelay(t *testing.T) {
+	needRetry := errors.New("ALSKDJFALKDSJF")
+	shouldRetry := func(e error) bool {
+		return e == needRetry
+	}
+
+	// initDelay is capped by maxDelay, so no delay can come close to 1000ms.
+	r := retry.New(shouldRetry, 5, 1000, 10)
+	start := time.Now()
+	err := r.Do(func() error { return needRetry })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// maxDelay of 0 means no delay at all.
+	r = retry.New(shouldRetry, 5, 1000, 0)
+	start = time.Now()
+	err = r.Do(func() error { return needRetry })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// equal delays stay constant.
+	r = retry.New(shouldRetry, 3, 10, 10)
+	count := 0
+	err = r.Do(func() error {
+		count = count + 1
+		return needRetry
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 3, count)
+}
+
+func TestNewChecked(t *testing.T) {
+	shouldRetry := func(e error) bool { return true }
+
+	r, err := retry.NewChecked(shouldRetry, 3, 1, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Do(func() error { return nil }))
+
+	cases := []struct {
+		maxAttempt, initDelay, maxDelay int
+		param                           string
+	}{
+		{0, 1, 2, "maxAttempt"},
+		{-1, 1, 2, "maxAttempt"},
+		{3, -1, 2, "initDelay"},
+		{3, 1, -2, "maxDelay"},
+		{3, 5, 2, "initDelay"},
+		{3, 1, math.MaxInt, "maxDelay"},
+	}
+	for _, c := range cases {
+		_, err := retry.NewChecked(shouldRetry, c.maxAttempt, c.initDelay, c.maxDelay)
+		var invalid *retry.ErrInvalidConfig
+		if assert.ErrorAs(t, err, &invalid) {
+			assert.Equal(t, c.param, invalid.Param)
+		}
+	}
+	_, err = retry.NewChecked(nil, 3, 1, 2)
+	var invalid *retry.ErrInvalidConfig
+	if assert.ErrorAs(t, err, &invalid) {
+		assert.Equal(t, "shouldRetry", invalid.Param)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, retry.New(func(e error) bool { return true }, 3, 1, 2).Validate())
+	assert.NoError(t, retry.New(func(e error) bool { return true }, 0, 1, 2).WithUnlimitedAttempts().Validate())
+
+	cases := []struct {
+		r     retry.Retry
+		param string
+	}{
+		{retry.Retry{}, "shouldRetry"},
+		{retry.New(nil, 3, 1, 2), "shouldRetry"},
+		{retry.New(func(e error) bool { return true }, 0, 1, 2), "maxAttempt"},
+		{retry.NewWithDurations(func(e error) bool { return true }, 3, -2, -1), "initDelay"},
+	}
+	for _, c := range cases {
+		count := 0
+		err := c.r.Do(func() error {
+			count++
+			return nil
+		})
+		var invalid *retry.ErrInvalidConfig
+		if assert.ErrorAs(t, err, &invalid) {
+			assert.Equal(t, c.param, invalid.Param)
+		}
+		assert.Equal(t, 0, count)
+		assert.Equal(t, err, c.r.Validate())
+	}
+}
+
+func TestWithNoJitter(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 4, 10, 20).WithNoJitter()
+	start := time.Now()
+	err := r.Do(func() error { return errors.New("fail") })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	// 10ms + 20ms + 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestWithAttemptTimeout(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 3, 0, 0).WithAttemptTimeout(10 * time.Millisecond)
+
+	count := 0
+	start := time.Now()
+	err := r.DoContext(context.Background(), func(ctx context.Context) error {
+		count = count + 1
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, 3, count)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAttemptTimeoutWithinDeadline(t *testing.T) {
+	r := retry.New(func(e error) bool { return errors.Is(e, context.DeadlineExceeded) }, 3, 0, 0).WithAttemptTimeout(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	parent, _ := ctx.Deadline()
+
+	count := 0
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		count = count + 1
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, parent, deadline)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// a timed-out attempt does not cancel the caller's context.
+	count = 0
+	err = r.WithAttemptTimeout(5*time.Millisecond).DoContext(ctx, func(attemptCtx context.Context) error {
+		count = count + 1
+		<-attemptCtx.Done()
+		assert.NoError(t, ctx.Err())
+		return attemptCtx.Err()
+	})
+	assert.Equal(t, 3, count)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+}
+
+func TestWithFullDeadlineForLastAttempt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	parentDeadline, _ := ctx.Deadline()
+
+	var deadlines []time.Time
+	collect := func(ctx context.Context) error {
+		d, _ := ctx.Deadline()
+		deadlines = append(deadlines, d)
+		return errors.New("fail")
+	}
+
+	r := retry.New(func(e error) bool { return true }, 3, 0, 0).WithAttemptTimeout(time.Second)
+	_ = r.WithFullDeadlineForLastAttempt().DoContext(ctx, collect)
+	assert.Len(t, deadlines, 3)
+	assert.True(t, deadlines[0].Before(parentDeadline))
+	assert.True(t, deadlines[1].Before(parentDeadline))
+	assert.Equal(t, parentDeadline, deadlines[2])
+
+	deadlines = nil
+	_ = r.DoContext(ctx, collect)
+	assert.True(t, deadlines[2].Before(parentDeadline))
+
+	// without a deadline on the context the last attempt keeps its timeout.
+	deadlines = nil
+	_ = r.WithFullDeadlineForLastAttempt().DoContext(context.Background(), collect)
+	assert.False(t, deadlines[2].IsZero())
+}
+
+func TestWithDeadlineSlicing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	var timeouts []time.Duration
+	count := 0
+	r := retry.New(func(e error) bool { return true }, 4, 0, 0).WithDeadlineSlicing()
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		count = count + 1
+		d, _ := ctx.Deadline()
+		timeouts = append(timeouts, time.Until(d))
+		if count == 1 {
+			// fail fast, leaving more time for the others.
+			return errors.New("fail")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 4, count)
+	assert.LessOrEqual(t, timeouts[0], 100*time.Millisecond)
+	assert.Greater(t, timeouts[1], 100*time.Millisecond)
+	assert.LessOrEqual(t, timeouts[1], 134*time.Millisecond)
+
+	// the attempt timeout still applies when it is shorter than the slice.
+	timeouts = nil
+	_ = r.WithAttemptTimeout(10*time.Millisecond).DoContext(context.Background(), func(ctx context.Context) error {
+		d, _ := ctx.Deadline()
+		timeouts = append(timeouts, time.Until(d))
+		return errors.New("fail")
+	})
+	for _, d := range timeouts {
+		assert.LessOrEqual(t, d, 10*time.Millisecond)
+	}
+}
+
+func TestWithIdempotencyGuard(t *testing.T) {
+	timeout := errors.New("timeout")
+	committed := false
+	count := 0
+	r := retry.New(func(e error) bool { return true }, 5, 0, 0).WithIdempotencyGuard(func(ctx context.Context, err error) bool {
+		assert.Equal(t, timeout, err)
+		return committed
+	})
+	err := r.Do(func() error {
+		count = count + 1
+		if count == 2 {
+			// the write goes through but the response is lost.
+			committed = true
+		}
+		return timeout
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestWithLatencyPrediction(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+			return errors.New("fail")
+		}
+	}
+	r := retry.New(func(e error) bool { return true }, 5, 0, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	count := 0
+	start := time.Now()
+	err := r.WithLatencyPrediction().DoContext(ctx, func(ctx context.Context) error {
+		count = count + 1
+		return slow(ctx)
+	})
+	assert.Equal(t, 2, count)
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), 115*time.Millisecond)
+
+	// without prediction the third attempt runs until the deadline.
+	ctx, cancel = context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	count = 0
+	_ = r.DoContext(ctx, func(ctx context.Context) error {
+		count = count + 1
+		return slow(ctx)
+	})
+	assert.Equal(t, 3, count)
+}
+
+func TestWithFixedRate(t *testing.T) {
+	var starts []time.Time
+	r := retry.New(func(e error) bool { return true }, 4, 50, 50).WithNoJitter().WithFixedRate()
+	_ = r.Do(func() error {
+		starts = append(starts, time.Now())
+		time.Sleep(30 * time.Millisecond)
+		return errors.New("fail")
+	})
+	assert.Len(t, starts, 4)
+	// 3 intervals of 50ms, rather than 3 * (30ms + 50ms).
+	assert.GreaterOrEqual(t, starts[3].Sub(starts[0]), 150*time.Millisecond)
+	assert.Less(t, starts[3].Sub(starts[0]), 230*time.Millisecond)
+
+	// an attempt longer than the delay is followed immediately without overlapping.
+	starts = nil
+	_ = r.Do(func() error {
+		starts = append(starts, time.Now())
+		time.Sleep(60 * time.Millisecond)
+		return errors.New("fail")
+	})
+	for i := 1; i < len(starts); i++ {
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), 60*time.Millisecond)
+		assert.Less(t, starts[i].Sub(starts[i-1]), 100*time.Millisecond)
+	}
+}
+
+func TestSubMillisecondDelays(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 10, 1000, 1000).WithDelays(50*time.Microsecond, 200*time.Microsecond).WithNoJitter()
+	start := time.Now()
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 10, count)
+	// 50us + 100us + 7 * 200us
+	assert.GreaterOrEqual(t, time.Since(start), 1550*time.Microsecond)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestNewWithDurations(t *testing.T) {
+	r := retry.NewWithDurations(func(e error) bool { return true }, 10, 500*time.Microsecond, 72*time.Hour).WithNoJitter()
+	assert.Equal(t, 500*time.Microsecond, r.Delay(1))
+	assert.Equal(t, time.Millisecond, r.Delay(2))
+	assert.Equal(t, 72*time.Hour, r.Delay(100))
+
+	r = retry.NewWithDurations(func(e error) bool { return true }, 10, time.Second, time.Millisecond).WithNoJitter()
+	assert.Equal(t, time.Millisecond, r.Delay(1))
+}
+
+func TestWithMaxTotalSleep(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 10, 20, 20).WithNoJitter().WithMaxTotalSleep(50 * time.Millisecond)
+	count := 0
+	start := time.Now()
+	err := r.Do(func() error {
+		count = count + 1
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("fail")
+	})
+	// 20ms + 20ms + 10ms of sleep, then nothing left.
+	assert.Equal(t, 4, count)
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestDelay(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 10, 10, 50).WithNoJitter()
+	var delays []time.Duration
+	for n := 1; n <= 5; n++ {
+		delays = append(delays, r.Delay(n))
+	}
+	ms := time.Millisecond
+	assert.Equal(t, []time.Duration{10 * ms, 20 * ms, 40 * ms, 50 * ms, 50 * ms}, delays)
+
+	r = retry.New(func(e error) bool { return true }, 10, 10, 50)
+	for n := 1; n <= 5; n++ {
+		assert.Less(t, r.Delay(n), delays[n-1])
+	}
+}
+
+func TestWithCompensation(t *testing.T) {
+	var compensated []int
+	r := retry.New(func(e error) bool { return true }, 3, 0, 0).WithCompensation(func(ctx context.Context, attempts []retry.AttemptError) {
+		for _, a := range attempts {
+			compensated = append(compensated, a.Attempt)
+		}
+	})
+
+	err := r.Do(func() error { return errors.New("partial write") })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, []int{1, 2, 3}, compensated)
+
+	compensated = nil
+	count := 0
+	err = r.Do(func() error {
+		count = count + 1
+		if count == 2 {
+			return nil
+		}
+		return errors.New("partial write")
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, compensated)
+}
+
+func TestWithHealthCheck(t *testing.T) {
+	up := true
+	count := 0
+	r := retry.New(func(e error) bool { return true }, 5, 0, 0).WithHealthCheck(func(context.Context) bool { return up })
+	err := r.Do(func() error {
+		count = count + 1
+		if count == 2 {
+			up = false
+		}
+		return errors.New("fail")
+	})
+	assert.Equal(t, 2, count)
+	assert.ErrorIs(t, err, retry.ErrDependencyDown)
+	var aborted *retry.ErrAborted
+	assert.ErrorAs(t, err, &aborted)
+	assert.Len(t, aborted.Attempts, 2)
+}
+
+func TestWithDelayHook(t *testing.T) {
+	var seen [][]retry.AttemptError
+	r := retry.New(func(e error) bool { return true }, 3, 0, 0).WithDelayHook(func(attempts []retry.AttemptError) time.Duration {
+		seen = append(seen, append([]retry.AttemptError(nil), attempts...))
+		return time.Duration(len(attempts)) * time.Millisecond
+	})
+	err := r.Do(func() error {
+		time.Sleep(time.Millisecond)
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Len(t, seen, 2)
+	assert.Len(t, seen[0], 1)
+	assert.Len(t, seen[1], 2)
+	assert.Equal(t, time.Duration(0), seen[0][0].Delay)
+	assert.Equal(t, time.Millisecond, seen[1][0].Delay)
+	assert.GreaterOrEqual(t, seen[1][1].Duration, time.Millisecond)
+
+	var exceeded *retry.ErrMaxAttemptExceeded
+	assert.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 2*time.Millisecond, exceeded.Attempts[1].Delay)
+	assert.Equal(t, time.Duration(0), exceeded.Attempts[2].Delay)
+}
+
+func TestWithDelayFunc(t *testing.T) {
+	type attemptErr struct {
+		attempt int
+		err     string
+	}
+	var seen []attemptErr
+	count := 0
+	r := retry.New(func(e error) bool { return true }, 3, 10000, 10000).WithDelayFunc(func(attempt int, err error) time.Duration {
+		seen = append(seen, attemptErr{attempt, err.Error()})
+		return time.Millisecond
+	})
+	start := time.Now()
+	err := r.Do(func() error {
+		count = count + 1
+		return fmt.Errorf("fail %d", count)
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []attemptErr{{1, "fail 1"}, {2, "fail 2"}}, seen)
+}
+
+func TestDoWithAttempt(t *testing.T) {
+	var attempts []int
+	r := retry.New(func(e error) bool { return true }, 5, 0, 0)
+	err := r.DoWithAttempt(func(attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt == 3 {
+			return nil
+		}
+		return errors.New("fail")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+}
+
+func TestWithOnRetry(t *testing.T) {
+	type retried struct {
+		attempt   int
+		err       string
+		nextDelay time.Duration
+	}
+	var seen []retried
+	count := 0
+	r := retry.New(func(e error) bool { return true }, 3, 1, 10).WithNoJitter().WithOnRetry(func(attempt int, err error, nextDelay time.Duration) {
+		seen = append(seen, retried{attempt, err.Error(), nextDelay})
+	})
+	err := r.Do(func() error {
+		count = count + 1
+		return fmt.Errorf("fail %d", count)
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, []retried{{1, "fail 1", time.Millisecond}, {2, "fail 2", 2 * time.Millisecond}}, seen)
+}
+
+func TestWithBeforeAndAfterAttempt(t *testing.T) {
+	var events []string
+	var took []time.Duration
+	r := retry.New(func(e error) bool { return true }, 3, 0, 0).
+		WithBeforeAttempt(func(attempt int) {
+			events = append(events, fmt.Sprintf("before %d", attempt))
+		}).
+		WithAfterAttempt(func(attempt int, err error, d time.Duration) {
+			events = append(events, fmt.Sprintf("after %d: %v", attempt, err))
+			took = append(took, d)
+		})
+	err := r.DoWithAttempt(func(attempt int) error {
+		time.Sleep(time.Millisecond)
+		if attempt == 2 {
+			return nil
+		}
+		return errors.New("fail")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"before 1", "after 1: fail", "before 2", "after 2: <nil>"}, events)
+	for _, d := range took {
+		assert.GreaterOrEqual(t, d, time.Millisecond)
+	}
+}
+
+func TestWithMaxElapsedTime(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 1000, 5, 5).WithNoJitter().WithMaxElapsedTime(30 * time.Millisecond)
+	count := 0
+	start := time.Now()
+	err := r.DoContext(context.Background(), func(ctx context.Context) error {
+		count = count + 1
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+	assert.Greater(t, count, 1)
+	assert.Less(t, count, 1000)
+}
+
+// clockLimiter waits for its delay on a fake clock.
+type clockLimiter struct {
+	clock *fakeClock
+	delay time.Duration
+}
+
+func (l clockLimiter) Wait(ctx context.Context) error {
+	l.clock.Sleep(l.delay)
+	return nil
+}
+
+func TestWithExcludeMandatedWaits(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := retry.NewWithDurations(func(e error) bool { return true }, 5, time.Second, time.Second).
+		WithNoJitter().WithClock(clock).WithMaxElapsedTime(10 * time.Second).WithExcludeMandatedWaits()
+
+	// the hinted delays do not count.
+	count := 0
+	err := r.DoContext(context.Background(), func(ctx context.Context) error {
+		count = count + 1
+		// the limit follows the clock alone, so it puts no real time deadline on the attempts.
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return &throttled{after: time.Hour}
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 5, count)
+
+	// nor do the waits for the limiter.
+	count = 0
+	err = r.WithLimiter(clockLimiter{clock: clock, delay: time.Hour}).Do(func() error {
+		count = count + 1
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 5, count)
+
+	// the backoff delays and the attempts do.
+	count = 0
+	clock.waited = nil
+	err = r.WithDelays(3*time.Second, 3*time.Second).Do(func() error {
+		count = count + 1
+		clock.Sleep(100 * time.Millisecond)
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, []time.Duration{3 * time.Second, 3 * time.Second, 3 * time.Second}, clock.waited)
+
+	// an attempt running over the limit on the clock ends the loop, however short it was in real time.
+	count = 0
+	err = r.Do(func() error {
+		count = count + 1
+		clock.Sleep(time.Minute)
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.Equal(t, 1, count)
+}
+
+func TestWithUnlimitedAttempts(t *testing.T) {
+	r := retry.New(func(e error) bool { return true }, 1, 0, 0).WithUnlimitedAttempts()
+	count := 0
+	err := r.Do(func() error {
+		count = count + 1
+		if count == 500 {
+			return nil
+		}
+		return errors.New("fail")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 500, count)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	parent, _ := ctx.Deadline()
+	err = retry.New(func(e error) bool { return true }, 0, 1, 1).WithUnlimitedAttempts().WithDeadlineSlicing().DoContext(ctx, func(ctx context.Context) error {
+		// no deadline slice is taken from the caller's.
+		deadline, _ := ctx.Deadline()
+		assert.Equal(t, parent, deadline)
+		return errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+}
+
+func TestWithDeadLetter(t *testing.T) {
+	type payload struct{}
+	var dead []any
+	r := retry.New(func(e error) bool { return e.Error() != "fatal" }, 2, 0, 0).WithDeadLetter(func(ctx context.Context, err error) {
+		assert.ErrorIs(t, err, retry.ErrExhausted)
+		dead = append(dead, ctx.Value(payload{}))
+	})
+	ctx := context.WithValue(context.Background(), payload{}, "order-1")
+
+	err := r.DoContext(ctx, func(ctx context.Context) error { return errors.New("fail") })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, []any{"order-1"}, dead)
+
+	// neither successes nor errors shouldRetry declines are dead-lettered.
+	assert.NoError(t, r.DoContext(ctx, func(ctx context.Context) error { return nil }))
+	assert.Error(t, r.DoContext(ctx, func(ctx context.Context) error { return errors.New("fatal") }))
+	assert.Len(t, dead, 1)
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retryavast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvastDo(t *testing.T) {
+	fail := errors.New("fail")
+	count := 0
+	var retried []uint
+	err := retryavast.Do(func() error {
+		count++
+		return fail
+	},
+		retryavast.Attempts(3),
+		retryavast.Delay(time.Millisecond),
+		retryavast.MaxDelay(2*time.Millisecond),
+		retryavast.OnRetry(func(n uint, err error) { retried = append(retried, n) }),
+	)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, fail)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []uint{0, 1}, retried)
+
+	count = 0
+	err = retryavast.Do(func() error {
+		count++
+		return fail
+	}, retryavast.RetryIf(func(err error) bool { return !errors.Is(err, fail) }))
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 1, count)
+
+	count = 0
+	err = retryavast.Do(func() error {
+		count++
+		return retryavast.Unrecoverable(fail)
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestAvastUnlimitedAttempts(t *testing.T) {
+	count := 0
+	v, err := retryavast.DoWithData(func() (int, error) {
+		count++
+		if count < 20 {
+			return 0, errors.New("fail")
+		}
+		return count, nil
+	}, retryavast.Attempts(0), retryavast.Delay(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, v)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = retryavast.Do(func() error { return errors.New("fail") }, retryavast.Attempts(0), retryavast.Context(ctx))
+	assert.ErrorIs(t, err, retry.ErrCanceled)
+}
+
+func TestAvastNew(t *testing.T) {
+	r := retryavast.New(retryavast.Delay(10*time.Millisecond), retryavast.MaxDelay(30*time.Millisecond)).WithNoJitter()
+	assert.Equal(t, 10*time.Millisecond, r.Delay(1))
+	assert.Equal(t, 30*time.Millisecond, r.Delay(3))
+	assert.NoError(t, r.Validate())
+}
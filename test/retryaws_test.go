@@ -0,0 +1,49 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/bluexlab/retry-go/retryaws"
+	"github.com/stretchr/testify/assert"
+)
+
+// awsV1Error mimics awserr.RequestFailure of the AWS SDK for Go v1.
+type awsV1Error struct {
+	code   string
+	status int
+}
+
+func (e *awsV1Error) Error() string     { return e.code }
+func (e *awsV1Error) Code() string      { return e.code }
+func (e *awsV1Error) Message() string   { return "" }
+func (e *awsV1Error) OrigErr() error    { return nil }
+func (e *awsV1Error) StatusCode() int   { return e.status }
+func (e *awsV1Error) RequestID() string { return "" }
+
+func TestAWSShouldRetry(t *testing.T) {
+	throttled := &smithy.OperationError{
+		ServiceID:     "DynamoDB",
+		OperationName: "PutItem",
+		Err:           &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException"},
+	}
+	assert.True(t, retryaws.ShouldRetry(throttled))
+	assert.True(t, retryaws.IsThrottle(throttled))
+	assert.True(t, retryaws.ShouldRetry(&awsV1Error{code: "RequestLimitExceeded", status: 400}))
+	assert.True(t, retryaws.IsThrottle(fmt.Errorf("describe: %w", &awsV1Error{code: "Throttling", status: 400})))
+
+	assert.True(t, retryaws.ShouldRetry(&awsV1Error{code: "InternalError", status: 500}))
+	assert.True(t, retryaws.ShouldRetry(&smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadGateway}},
+		Err:      errors.New("bad gateway"),
+	}))
+	assert.False(t, retryaws.IsThrottle(&awsV1Error{code: "InternalError", status: 500}))
+
+	assert.False(t, retryaws.ShouldRetry(&smithy.GenericAPIError{Code: "ConditionalCheckFailedException"}))
+	assert.False(t, retryaws.ShouldRetry(&awsV1Error{code: "AccessDenied", status: 403}))
+	assert.False(t, retryaws.ShouldRetry(errors.New("plain")))
+}
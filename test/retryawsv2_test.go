@@ -0,0 +1,53 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retryaws"
+	"github.com/bluexlab/retry-go/retryawsv2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryer(t *testing.T) {
+	var r aws.RetryerV2 = retryawsv2.NewRetryer(retry.NewWithDurations(retryaws.ShouldRetry, 4, 10*time.Millisecond, time.Second).WithNoJitter())
+	apiErr := &smithy.GenericAPIError{Code: "ThrottlingException"}
+	assert.True(t, r.IsErrorRetryable(apiErr))
+	assert.False(t, r.IsErrorRetryable(&smithy.GenericAPIError{Code: "ValidationException"}))
+	assert.False(t, r.IsErrorRetryable(retry.Unrecoverable(apiErr)))
+	assert.Equal(t, 4, r.MaxAttempts())
+
+	delay, err := r.RetryDelay(1, apiErr)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Millisecond, delay)
+	delay, err = r.RetryDelay(3, apiErr)
+	assert.NoError(t, err)
+	assert.Equal(t, 40*time.Millisecond, delay)
+	delay, err = r.RetryDelay(1, retry.MarkRetryable(&throttled{after: time.Second}))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, delay)
+
+	release, err := r.GetRetryToken(context.Background(), apiErr)
+	assert.NoError(t, err)
+	assert.NoError(t, release(nil))
+	release, err = r.GetAttemptToken(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, release(errors.New("fail")))
+	assert.NoError(t, r.GetInitialToken()(nil))
+
+	unlimited := retryawsv2.NewRetryer(retry.New(retryaws.ShouldRetry, 1, 0, 0).WithUnlimitedAttempts())
+	assert.Equal(t, 0, unlimited.MaxAttempts())
+}
+
+func TestRetryerSuspended(t *testing.T) {
+	gate := retry.NewFailureRateGate(time.Second, 0.5, 1, time.Hour)
+	r := retryawsv2.NewRetryer(retry.New(func(error) bool { return true }, 3, 0, 0).WithFailureRateGate(gate))
+	gate.Record(true)
+	_, err := r.RetryDelay(1, errors.New("fail"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retrybackoff"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromBackOff(t *testing.T) {
+	newBackOff := func() backoff.BackOff {
+		return backoff.WithMaxRetries(backoff.NewConstantBackOff(10*time.Millisecond), 2)
+	}
+	r := retry.New(func(error) bool { return true }, 5, 0, 0).WithBackoff(retrybackoff.FromBackOff(newBackOff)).WithNoJitter()
+	assert.Equal(t, 10*time.Millisecond, r.Delay(1))
+	assert.Equal(t, 10*time.Millisecond, r.Delay(2))
+	assert.Equal(t, 10*time.Millisecond, r.Delay(3))
+
+	exponential := func() backoff.BackOff {
+		b := backoff.NewExponentialBackOff()
+		b.InitialInterval = time.Millisecond
+		b.RandomizationFactor = 0
+		b.Multiplier = 2
+		b.Reset()
+		return b
+	}
+	r = r.WithBackoff(retrybackoff.FromBackOff(exponential))
+	assert.Equal(t, time.Millisecond, r.Delay(1))
+	assert.Equal(t, 4*time.Millisecond, r.Delay(3))
+}
+
+func TestToBackOff(t *testing.T) {
+	r := retry.New(func(error) bool { return false }, 3, 1, 1000).WithNoJitter()
+	b := retrybackoff.ToBackOff(r)
+	assert.Equal(t, time.Millisecond, b.NextBackOff())
+	assert.Equal(t, 2*time.Millisecond, b.NextBackOff())
+	assert.Equal(t, backoff.Stop, b.NextBackOff())
+	b.Reset()
+	assert.Equal(t, time.Millisecond, b.NextBackOff())
+
+	count := 0
+	err := backoff.Retry(func() error {
+		count++
+		return errors.New("fail")
+	}, retrybackoff.ToBackOff(r))
+	assert.EqualError(t, err, "fail")
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retrycache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader(t *testing.T) {
+	down := false
+	calls := 0
+	unavailable := errors.New("config service unavailable")
+	l := retrycache.NewLoader(retry.New(func(error) bool { return true }, 2, 0, 0), func(ctx context.Context, key string) (int, error) {
+		calls = calls + 1
+		if down {
+			return 0, unavailable
+		}
+		return len(key), nil
+	})
+	ctx := context.Background()
+
+	v, age, err := l.Load(ctx, "flags")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, v)
+	assert.Zero(t, age)
+
+	// the last value survives the exhaustion of the load.
+	down = true
+	time.Sleep(5 * time.Millisecond)
+	calls = 0
+	v, age, err = l.Load(ctx, "flags")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, v)
+	assert.GreaterOrEqual(t, age, 5*time.Millisecond)
+	assert.Equal(t, 2, calls)
+
+	// no value to fall back on.
+	_, _, err = l.Load(ctx, "limits")
+	assert.ErrorIs(t, err, unavailable)
+	l.Forget("flags")
+	_, _, err = l.Load(ctx, "flags")
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+
+	// nor once the caller gave up.
+	down = false
+	_, _, err = l.Load(ctx, "flags")
+	assert.NoError(t, err)
+	down = true
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = l.Load(canceled, "flags")
+	assert.ErrorIs(t, err, retry.ErrCanceled)
+}
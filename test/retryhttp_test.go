@@ -0,0 +1,99 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retryhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackpressure(t *testing.T) {
+	overloaded := false
+	shedding := false
+	policy := retry.New(func(error) bool { return true }, 10, 0, 0).WithDelays(time.Second, 8*time.Second).WithNoJitter()
+	handler := retryhttp.NewBackpressure(policy, func(*http.Request) bool { return shedding }).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if overloaded {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		}),
+	)
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	rec := serve()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+
+	overloaded = true
+	var advised []int
+	for i := 0; i < 3; i++ {
+		rec = serve()
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		n, _ := strconv.Atoi(rec.Header().Get("Retry-After"))
+		advised = append(advised, n)
+	}
+	shedding = true
+	rec = serve()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	n, _ := strconv.Atoi(rec.Header().Get("Retry-After"))
+	advised = append(advised, n)
+	assert.Equal(t, []int{1, 2, 4, 8}, advised)
+
+	// recovery resets the backoff.
+	overloaded, shedding = false, false
+	assert.Equal(t, http.StatusOK, serve().Code)
+	overloaded = true
+	assert.Equal(t, "1", serve().Header().Get("Retry-After"))
+}
+
+func TestBackpressureFlushAndHijack(t *testing.T) {
+	policy := retry.New(func(error) bool { return true }, 10, 1000, 1000)
+	handler := retryhttp.NewBackpressure(policy, nil).Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/hijack" {
+				conn, buf, err := w.(http.Hijacker).Hijack()
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+				_ = buf.Flush()
+				return
+			}
+			_, _ = w.Write([]byte("event: 1\n\n"))
+			w.(http.Flusher).Flush()
+		}),
+	)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for path, want := range map[string]string{"/events": "event: 1\n\n", "/hijack": "hijacked"} {
+		resp, err := http.Get(server.URL + path)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(body))
+	}
+
+	// a recorder cannot be hijacked.
+	rec := httptest.NewRecorder()
+	retryhttp.NewBackpressure(policy, nil).Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _, err := w.(http.Hijacker).Hijack()
+		assert.ErrorIs(t, err, http.ErrNotSupported)
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+}
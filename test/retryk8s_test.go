@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retryk8s"
+	"github.com/bluexlab/retry-go/retrytest"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestFromBackoff(t *testing.T) {
+	always := func(error) bool { return true }
+	fail := errors.New("fail")
+
+	rec := retrytest.NewRecorder(retryk8s.FromBackoff(wait.Backoff{Duration: 10 * time.Millisecond, Factor: 3, Steps: 4}, always))
+	assert.ErrorIs(t, rec.Do(func() error { return fail }), retry.ErrExhausted)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 90 * time.Millisecond}, rec.Delays())
+
+	// the condition is not checked again once the delays reach the cap.
+	rec = retrytest.NewRecorder(retryk8s.FromBackoff(wait.Backoff{Duration: 10 * time.Millisecond, Factor: 2, Steps: 10, Cap: 30 * time.Millisecond}, always))
+	assert.ErrorIs(t, rec.Do(func() error { return fail }), retry.ErrExhausted)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond}, rec.Delays())
+
+	rec = retrytest.NewRecorder(retryk8s.FromBackoff(wait.Backoff{Duration: time.Millisecond, Steps: 5}, always))
+	assert.ErrorIs(t, rec.Do(func() error { return fail }), retry.ErrExhausted)
+	assert.Equal(t, 4, rec.Retries())
+	assert.Equal(t, []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond, time.Millisecond}, rec.Delays())
+
+	rec = retrytest.NewRecorder(retryk8s.FromBackoff(wait.Backoff{Duration: 10 * time.Millisecond, Factor: 1, Jitter: 0.5, Steps: 3}, always))
+	assert.ErrorIs(t, rec.Do(func() error { return fail }), retry.ErrExhausted)
+	for _, d := range rec.Delays() {
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.LessOrEqual(t, d, 15*time.Millisecond)
+	}
+
+	var invalid *retry.ErrInvalidConfig
+	assert.ErrorAs(t, retryk8s.FromBackoff(wait.Backoff{Duration: time.Millisecond}, always).Do(func() error { return nil }), &invalid)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	r := retry.New(func(error) bool { return false }, 3, 0, 0)
+	count := 0
+	err := retryk8s.ExponentialBackoff(context.Background(), r, func(ctx context.Context) (bool, error) {
+		count++
+		return count == 2, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count = 0
+	err = retryk8s.ExponentialBackoff(context.Background(), r, func(ctx context.Context) (bool, error) {
+		count++
+		return false, nil
+	})
+	assert.Equal(t, wait.ErrWaitTimeout, err)
+	assert.True(t, wait.Interrupted(err))
+	assert.Equal(t, 3, count)
+
+	fail := errors.New("fail")
+	err = retryk8s.ExponentialBackoff(context.Background(), r.WithRetryIf(func(error) bool { return true }), func(ctx context.Context) (bool, error) {
+		return false, fail
+	})
+	assert.Equal(t, fail, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = retryk8s.ExponentialBackoff(ctx, r, func(ctx context.Context) (bool, error) {
+		cancel()
+		return false, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,190 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retryqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	q, err := retryqueue.Open(path, retry.New(func(error) bool { return true }, 5, 1, 1))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	delivered := make(chan string, 1)
+	count := 0
+	q.Handle("webhook", func(ctx context.Context, payload []byte) error {
+		count = count + 1
+		if count < 3 {
+			return errors.New("endpoint down")
+		}
+		delivered <- string(payload)
+		return nil
+	})
+	_, err = q.Enqueue("webhook", []byte(`{"event":"paid"}`))
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	select {
+	case payload := <-delivered:
+		assert.Equal(t, `{"event":"paid"}`, payload)
+	case <-time.After(time.Second):
+		t.Fatal("the job was not delivered")
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 0, q.Len())
+	assert.NoError(t, q.Close())
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	policy := retry.New(func(error) bool { return true }, 5, 50, 50).WithNoJitter()
+	q, err := retryqueue.Open(path, policy)
+	assert.NoError(t, err)
+
+	failed := make(chan struct{})
+	q.Handle("email", func(ctx context.Context, payload []byte) error {
+		close(failed)
+		return errors.New("smtp down")
+	})
+	_, err = q.Enqueue("email", []byte("hello"))
+	assert.NoError(t, err)
+	_, err = q.Enqueue("unknown", nil)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	<-failed
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+	// the job without a handler is given up on.
+	assert.Equal(t, 1, q.Len())
+	assert.NoError(t, q.Close())
+
+	// a torn write at the end of the log is ignored.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"op":"do`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	q, err = retryqueue.Open(path, policy)
+	assert.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, 1, q.Len())
+	delivered := make(chan string, 1)
+	q.Handle("email", func(ctx context.Context, payload []byte) error {
+		delivered <- string(payload)
+		return nil
+	})
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+	select {
+	case payload := <-delivered:
+		assert.Equal(t, "hello", payload)
+	case <-time.After(time.Second):
+		t.Fatal("the job did not resume")
+	}
+}
+
+func TestQueueDeadLetter(t *testing.T) {
+	q, err := retryqueue.Open(filepath.Join(t.TempDir(), "queue.log"), retry.New(func(error) bool { return true }, 2, 0, 0))
+	assert.NoError(t, err)
+	defer q.Close()
+
+	dead := make(chan retryqueue.Job, 1)
+	var deadErr error
+	q.WithDeadLetter(func(ctx context.Context, job retryqueue.Job, err error) {
+		// the job is still in the log, should the process stop now.
+		assert.Equal(t, 1, q.Len())
+		deadErr = err
+		dead <- job
+	})
+	q.Handle("webhook", func(ctx context.Context, payload []byte) error {
+		return errors.New("gone")
+	})
+	id, err := q.Enqueue("webhook", []byte("payload"))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+	select {
+	case job := <-dead:
+		assert.Equal(t, retryqueue.Job{ID: id, Handler: "webhook", Payload: []byte("payload"), Attempts: 2, LastError: "gone"}, job)
+		assert.EqualError(t, deadErr, "gone")
+	case <-time.After(time.Second):
+		t.Fatal("the job was not dead-lettered")
+	}
+	assert.Eventually(t, func() bool { return q.Len() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestQueueWaitsOutSuspension(t *testing.T) {
+	g := retry.NewFailureRateGate(time.Second, 0.5, 1, 20*time.Millisecond)
+	g.Record(true)
+	q, err := retryqueue.Open(filepath.Join(t.TempDir(), "queue.log"), retry.New(func(error) bool { return true }, 5, 1, 1).WithFailureRateGate(g))
+	assert.NoError(t, err)
+	defer q.Close()
+
+	q.WithDeadLetter(func(ctx context.Context, job retryqueue.Job, err error) {
+		t.Error("the job was given up on while retries were suspended")
+	})
+	delivered := make(chan struct{})
+	count := 0
+	q.Handle("webhook", func(ctx context.Context, payload []byte) error {
+		count = count + 1
+		if count < 3 {
+			return errors.New("endpoint down")
+		}
+		close(delivered)
+		return nil
+	})
+	_, err = q.Enqueue("webhook", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("the job was not delivered")
+	}
+}
+
+func TestQueueCompactsLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	q, err := retryqueue.Open(path, retry.New(func(error) bool { return true }, 5, 1, 1))
+	assert.NoError(t, err)
+	defer q.Close()
+
+	q.Handle("webhook", func(ctx context.Context, payload []byte) error { return nil })
+	const jobs = 1000
+	for i := 0; i < jobs; i++ {
+		_, err := q.Enqueue("webhook", nil)
+		assert.NoError(t, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = q.Run(ctx) }()
+	assert.Eventually(t, func() bool { return q.Len() == 0 }, 10*time.Second, time.Millisecond)
+
+	// the records of the jobs done were dropped rather than piling up.
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Less(t, bytes.Count(data, []byte("\n")), jobs)
+}
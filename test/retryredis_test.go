@@ -0,0 +1,57 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retryredis"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type redisReply string
+
+func (e redisReply) Error() string { return string(e) }
+func (redisReply) RedisError()     {}
+
+func TestRedisShouldRetry(t *testing.T) {
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1})
+	defer client.Close()
+	ctx := context.Background()
+
+	m.SetError("LOADING Redis is loading the dataset in memory")
+	err := client.Get(ctx, "k").Err()
+	assert.True(t, retryredis.ShouldRetry(err))
+
+	m.SetError("")
+	count := 0
+	err = retry.New(retryredis.ShouldRetry, 3, 0, 0).DoContext(ctx, func(ctx context.Context) error {
+		count = count + 1
+		if count == 1 {
+			m.SetError("READONLY You can't write against a read only replica.")
+		} else {
+			m.SetError("")
+		}
+		return client.Set(ctx, "k", "v", 0).Err()
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.False(t, retryredis.ShouldRetry(client.Incr(ctx, "k").Err()))
+	assert.False(t, retryredis.ShouldRetry(redis.Nil))
+
+	assert.True(t, retryredis.ShouldRetry(redisReply("MOVED 3999 127.0.0.1:6381")))
+	assert.True(t, retryredis.ShouldRetry(fmt.Errorf("get: %w", redisReply("ASK 3999 127.0.0.1:6381"))))
+	assert.True(t, retryredis.ShouldRetry(redisReply("CLUSTERDOWN The cluster is down")))
+	assert.True(t, retryredis.ShouldRetry(errors.New("redis: connection pool timeout")))
+	assert.True(t, retryredis.ShouldRetry(io.EOF))
+	assert.True(t, retryredis.ShouldRetry(&net.OpError{Op: "read", Err: errors.New("i/o timeout")}))
+	assert.False(t, retryredis.ShouldRetry(context.DeadlineExceeded))
+}
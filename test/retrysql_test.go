@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retrysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var errSerialization = errors.New("could not serialize access due to concurrent update")
+
+// txLog records the transactions of the fake driver.
+type txLog struct {
+	begun, committed, rolledBack int
+	failCommits                  int
+}
+
+type fakeConnector struct {
+	log *txLog
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn(c), nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	log *txLog
+}
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	c.log.begun++
+	return fakeTx(c), nil
+}
+
+type fakeTx struct {
+	log *txLog
+}
+
+func (t fakeTx) Commit() error {
+	if t.log.failCommits > 0 {
+		t.log.failCommits--
+		return errSerialization
+	}
+	t.log.committed++
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.log.rolledBack++
+	return nil
+}
+
+func TestTx(t *testing.T) {
+	log := &txLog{}
+	db := sql.OpenDB(fakeConnector{log})
+	defer db.Close()
+	policy := retry.New(func(e error) bool { return errors.Is(e, errSerialization) }, 3, 0, 0)
+	ctx := context.Background()
+
+	count := 0
+	err := retrysql.Tx(ctx, db, policy, func(tx *sql.Tx) error {
+		count = count + 1
+		if count == 1 {
+			return errSerialization
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, txLog{begun: 2, committed: 1, rolledBack: 1}, *log)
+
+	// a failed commit is retried as well.
+	*log = txLog{failCommits: 1}
+	assert.NoError(t, retrysql.Tx(ctx, db, policy, func(tx *sql.Tx) error { return nil }))
+	assert.Equal(t, 2, log.begun)
+	assert.Equal(t, 1, log.committed)
+
+	*log = txLog{}
+	permanent := errors.New("unique violation")
+	err = retrysql.Tx(ctx, db, policy, func(tx *sql.Tx) error { return permanent })
+	assert.Equal(t, permanent, err)
+	assert.Equal(t, txLog{begun: 1, rolledBack: 1}, *log)
+
+	*log = txLog{}
+	assert.Panics(t, func() {
+		_ = retrysql.Tx(ctx, db, policy, func(tx *sql.Tx) error { panic("boom") })
+	})
+	assert.Equal(t, txLog{begun: 1, rolledBack: 1}, *log)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/retrytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := retrytest.NewRecorder(retry.New(func(error) bool { return true }, 5, 1000, 60000).WithNoJitter())
+	fail := errors.New("fail")
+	count := 0
+	start := time.Now()
+	err := fetchWith(rec, func() error {
+		count++
+		if count < 4 {
+			return fail
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, rec.Calls())
+	assert.Equal(t, 3, rec.Retries())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, rec.Delays())
+	assert.Equal(t, retrytest.Attempt{Call: 1, Attempt: 2, Err: fail, Delay: 2 * time.Second}, rec.Attempts()[1])
+	assert.Equal(t, retrytest.Attempt{Call: 1, Attempt: 4}, rec.Attempts()[3])
+}
+
+func TestRecorderInject(t *testing.T) {
+	rec := retrytest.NewRecorder(retry.New(func(error) bool { return true }, 3, 10, 10).WithNoJitter())
+	throttled := errors.New("throttled")
+	rec.Inject(throttled, nil)
+
+	var attempts []int
+	err := rec.DoWithAttempt(func(attempt int) error {
+		attempts = append(attempts, attempt)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, attempts)
+	assert.Equal(t, []retrytest.Attempt{
+		{Call: 1, Attempt: 1, Err: throttled, Injected: true, Delay: 10 * time.Millisecond},
+		{Call: 1, Attempt: 2},
+	}, rec.Attempts())
+
+	rec.Reset()
+	rec.Inject(throttled, throttled, throttled)
+	err = rec.Do(func() error { return nil })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 2, rec.Retries())
+}
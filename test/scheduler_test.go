@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	w := retry.NewTimerWheel(time.Millisecond, 64)
+	defer w.Stop()
+	s := retry.NewScheduler(retry.New(func(error) bool { return true }, 3, 5, 5), w, 4)
+	defer s.Close()
+
+	const n = 1000
+	before := runtime.NumGoroutine()
+	var attempts atomic.Int32
+	var results []<-chan error
+	for i := 0; i < n; i++ {
+		var count atomic.Int32
+		results = append(results, s.Submit(context.Background(), func(ctx context.Context) error {
+			attempts.Add(1)
+			if count.Add(1) < 3 {
+				return errors.New("fail")
+			}
+			return nil
+		}))
+	}
+	// the parked operations do not hold goroutines.
+	assert.Less(t, runtime.NumGoroutine()-before, 50)
+	for _, done := range results {
+		assert.NoError(t, <-done)
+	}
+	assert.Equal(t, int32(3*n), attempts.Load())
+
+	err := <-s.Submit(context.Background(), func(ctx context.Context) error { return errors.New("fail") })
+	var exceeded *retry.ErrMaxAttemptExceeded
+	if assert.ErrorAs(t, err, &exceeded) {
+		assert.Equal(t, 3, exceeded.AttemptsMade)
+		assert.Len(t, exceeded.Attempts, 3)
+	}
+	fatal := errors.New("fatal")
+	assert.Equal(t, fatal, <-s.Submit(context.Background(), func(ctx context.Context) error { return retry.Unrecoverable(fatal) }))
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	w := retry.NewTimerWheel(time.Millisecond, 64)
+	defer w.Stop()
+	s := retry.NewScheduler(retry.New(func(error) bool { return true }, 3, 10000, 10000), w, 1)
+
+	// a parked operation gives up as soon as its context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := s.Submit(ctx, func(ctx context.Context) error { return errors.New("fail") })
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, retry.ErrCanceled)
+	case <-time.After(time.Second):
+		t.Fatal("the parked operation was not woken up")
+	}
+
+	// closing drops the parked operations.
+	done = s.Submit(context.Background(), func(ctx context.Context) error { return errors.New("fail") })
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+	err := <-done
+	assert.ErrorIs(t, err, retry.ErrSchedulerClosed)
+	assert.ErrorContains(t, err, "fail")
+	assert.ErrorIs(t, <-s.Submit(context.Background(), func(ctx context.Context) error { return nil }), retry.ErrSchedulerClosed)
+}
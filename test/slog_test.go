@@ -0,0 +1,39 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "elapsed" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	r := retry.New(func(error) bool { return true }, 2, 1, 1).WithNoJitter().WithLogger(logger)
+
+	err := r.Do(func() error { return errors.New("boom") })
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		`level=DEBUG msg=retrying attempt=1 error=boom delay=1ms`,
+		`level=WARN msg="retry attempts exhausted" attempts=2 error=boom`,
+	}, lines)
+
+	// nothing is logged on success.
+	buf.Reset()
+	assert.NoError(t, r.Do(func() error { return nil }))
+	assert.Empty(t, buf.String())
+}
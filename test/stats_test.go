@@ -0,0 +1,35 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	fatal := errors.New("fatal")
+	r := retry.New(func(err error) bool { return err != fatal }, 3, 0, 0)
+	assert.NoError(t, r.Do(func() error { return nil }))
+	assert.Equal(t, retry.Stats{}, r.Stats())
+
+	r = r.WithStats()
+	count := 0
+	assert.NoError(t, r.Do(func() error {
+		count = count + 1
+		if count < 3 {
+			return errors.New("fail")
+		}
+		return nil
+	}))
+	assert.Error(t, r.Do(func() error { return errors.New("fail") }))
+	assert.Error(t, r.Do(func() error { return fatal }))
+	// copies share the statistics.
+	assert.NoError(t, r.WithNoJitter().Do(func() error { return nil }))
+
+	s := r.Stats()
+	assert.Equal(t, retry.Stats{Calls: 4, Attempts: 8, Retries: 4, Successes: 2, Exhaustions: 1, Failures: 1}, s)
+	assert.Equal(t, 2.0, s.AvgAttempts())
+	assert.Equal(t, 0.0, retry.Stats{}.AvgAttempts())
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveThrottle(t *testing.T) {
+	throttled := errors.New("ThrottlingException")
+	isThrottle := func(e error) bool { return e == throttled }
+	a := retry.NewAdaptiveThrottle(isThrottle, 2, time.Minute)
+	assert.Zero(t, a.Probability())
+
+	// other errors count as accepted.
+	a.Record(nil)
+	a.Record(errors.New("not found"))
+	assert.Zero(t, a.Probability())
+
+	for i := 0; i < 98; i++ {
+		a.Record(throttled)
+	}
+	// (100 - 2 * 2) / 101
+	assert.InDelta(t, 96.0/101, a.Probability(), 1e-9)
+
+	r := retry.New(func(error) bool { return true }, 10, 0, 0).WithAdaptiveThrottle(a)
+	calls, gaveUp := 0, 0
+	for i := 0; i < 100; i++ {
+		count := 0
+		err := r.Do(func() error {
+			count = count + 1
+			return throttled
+		})
+		calls += count
+		if errors.Is(err, retry.ErrThrottled) {
+			gaveUp++
+		}
+		// first attempts are not held back by default.
+		assert.GreaterOrEqual(t, count, 1)
+	}
+	assert.Greater(t, gaveUp, 90)
+	assert.Less(t, calls, 200)
+
+	count := 0
+	err := retry.New(func(error) bool { return true }, 10, 0, 0).WithAdaptiveThrottle(a.ThrottleFirstAttempts()).Do(func() error {
+		count = count + 1
+		return nil
+	})
+	if count == 0 {
+		assert.ErrorIs(t, err, retry.ErrThrottled)
+	}
+}
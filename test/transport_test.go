@@ -0,0 +1,189 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/bluexlab/retry-go/idempotency"
+	"github.com/bluexlab/retry-go/retryhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	var bodies []string
+	failures := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("busy"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	policy := retry.New(func(e error) bool {
+		var status *retryhttp.StatusError
+		return errors.As(e, &status)
+	}, 3, 1, 1)
+	client := &http.Client{Transport: retryhttp.NewTransport(policy, nil)}
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL, bytes.NewReader([]byte("payload")))
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+
+	// a POST is not retried unless it carries an idempotency key.
+	bodies, failures = nil, 5
+	resp, err = client.Post(server.URL, "text/plain", bytes.NewReader([]byte("order")))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, bodies, 1)
+
+	bodies = nil
+	req, _ = http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte("order")))
+	req.Header.Set(idempotency.Header, idempotency.NewKey())
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	// the last response returns once the policy gives up.
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "busy", string(body))
+	assert.Equal(t, []string{"order", "order", "order"}, bodies)
+}
+
+func TestTransportError(t *testing.T) {
+	attempts := 0
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, io.ErrUnexpectedEOF
+	})
+	policy := retry.New(func(e error) bool { return errors.Is(e, io.ErrUnexpectedEOF) }, 3, 0, 0)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	_, err := retryhttp.NewTransport(policy, base).RoundTrip(req)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, 3, attempts)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClassifiers(t *testing.T) {
+	status := func(code int) error {
+		return &retryhttp.StatusError{StatusCode: code, Status: http.StatusText(code)}
+	}
+	assert.True(t, retryhttp.DefaultShouldRetry(status(http.StatusTooManyRequests)))
+	assert.True(t, retryhttp.DefaultShouldRetry(status(http.StatusGatewayTimeout)))
+	assert.False(t, retryhttp.DefaultShouldRetry(status(http.StatusInternalServerError)))
+	assert.False(t, retryhttp.DefaultShouldRetry(status(http.StatusNotFound)))
+	assert.True(t, retryhttp.DefaultShouldRetry(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}))
+	assert.True(t, retryhttp.DefaultShouldRetry(fmt.Errorf("body: %w", io.ErrUnexpectedEOF)))
+	assert.False(t, retryhttp.DefaultShouldRetry(errors.New("x509: certificate signed by unknown authority")))
+
+	onServerError := retryhttp.RetryOnStatus(http.StatusInternalServerError, http.StatusServiceUnavailable)
+	assert.True(t, onServerError(fmt.Errorf("call: %w", status(http.StatusInternalServerError))))
+	assert.False(t, onServerError(status(http.StatusTooManyRequests)))
+	assert.False(t, onServerError(io.ErrUnexpectedEOF))
+}
+
+func TestTransportWithClassifier(t *testing.T) {
+	codes := []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusOK}
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(codes[attempts])
+		attempts++
+	}))
+	defer server.Close()
+
+	transport := retryhttp.NewTransport(retry.New(retryhttp.RetryOnStatus(http.StatusInternalServerError), 3, 0, 0), nil)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	// 404 is not retried.
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+
+	codes = []int{http.StatusInternalServerError, http.StatusOK}
+	attempts = 0
+	resp, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestTransportRetryAfter(t *testing.T) {
+	var delays []time.Duration
+	headers := []string{"1", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), "garbage"}
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if attempts < len(headers) {
+			w.Header().Set("Retry-After", headers[attempts])
+			attempts++
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		attempts++
+	}))
+	defer server.Close()
+
+	policy := retry.New(retryhttp.DefaultShouldRetry, 5, 1, 1).WithClock(&fakeClock{now: time.Now()}).WithOnRetry(func(attempt int, err error, nextDelay time.Duration) {
+		delays = append(delays, nextDelay)
+	})
+	transport := retryhttp.NewTransport(policy, nil).WithMaxRetryAfter(time.Minute)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 4, attempts)
+	assert.Len(t, delays, 3)
+	assert.Equal(t, time.Second, delays[0])
+	// the hour asked for is shortened.
+	assert.Equal(t, time.Minute, delays[1])
+	// an unparsable header falls back to the policy.
+	assert.LessOrEqual(t, delays[2], time.Millisecond)
+}
+
+func TestTransportRetryAfterDefaultCap(t *testing.T) {
+	var delays []time.Duration
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "99999999")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	policy := retry.New(retryhttp.DefaultShouldRetry, 3, 1, 1).WithClock(&fakeClock{now: time.Now()}).WithOnRetry(func(attempt int, err error, nextDelay time.Duration) {
+		delays = append(delays, nextDelay)
+	})
+	resp, err := (&http.Client{Transport: retryhttp.NewTransport(policy, nil)}).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{retryhttp.DefaultMaxRetryAfter}, delays)
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRetryIfValue(t *testing.T) {
+	r := retry.New(func(error) bool { return false }, 3, 0, 0)
+	r = retry.WithRetryIfValue(r, func(status int) bool { return status >= 500 })
+
+	statuses := []int{503, 502, 200}
+	count := 0
+	status, err := retry.Retry2(r, func() (int, error) {
+		count = count + 1
+		return statuses[count-1], nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 3, count)
+
+	// the last value returns with the error once exhausted.
+	status, err = retry.Retry2Func1(r, func(status int) (int, error) { return status, nil }, 500)
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, retry.ErrUnacceptedValue)
+	assert.Equal(t, 500, status)
+
+	// errors are still up to shouldRetry.
+	count = 0
+	_, err = retry.Retry2(r, func() (int, error) {
+		count = count + 1
+		return 0, errors.New("fatal")
+	})
+	assert.EqualError(t, err, "fatal")
+	assert.Equal(t, 1, count)
+
+	// calls returning another type ignore the predicate.
+	s, err := retry.Retry2(r, func() (string, error) { return "", nil })
+	assert.NoError(t, err)
+	assert.Empty(t, s)
+}
+
+func TestDoValue(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, 21)
+
+	count := 0
+	v, err := retry.DoValue(ctx, r, func(ctx context.Context) (int, error) {
+		count = count + 1
+		if count < 2 {
+			return 0, errors.New("fail")
+		}
+		return ctx.Value(key{}).(int) * 2, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	count = 0
+	n, s, err := retry.DoValues2(ctx, r, func(ctx context.Context) (int, string, error) {
+		count = count + 1
+		if count < 3 {
+			return 0, "", errors.New("fail")
+		}
+		return count, "done", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "done", s)
+
+	_, _, err = retry.DoValues2(ctx, r, func(ctx context.Context) (int, string, error) {
+		return 0, "", errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+}
+
+func TestDoValues3(t *testing.T) {
+	r := retry.New(func(error) bool { return true }, 3, 0, 0)
+	count := 0
+	get := func() (string, map[string]string, int, error) {
+		count = count + 1
+		if count < 2 {
+			return "", nil, 0, errors.New("fail")
+		}
+		return "object", map[string]string{"etag": "v2"}, 200, nil
+	}
+	body, meta, status, err := retry.Retry4(r, get)
+	assert.NoError(t, err)
+	assert.Equal(t, "object", body)
+	assert.Equal(t, map[string]string{"etag": "v2"}, meta)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 2, count)
+
+	_, _, _, err = retry.DoValues3(context.Background(), r, func(context.Context) (int, int, int, error) {
+		return 0, 0, 0, errors.New("fail")
+	})
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+}
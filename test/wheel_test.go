@@ -0,0 +1,66 @@
+package test
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimerWheel(t *testing.T) {
+	w := retry.NewTimerWheel(time.Millisecond, 8)
+	defer w.Stop()
+
+	for _, d := range []time.Duration{0, time.Millisecond, 5 * time.Millisecond, 30 * time.Millisecond} {
+		start := time.Now()
+		<-w.After(d)
+		assert.GreaterOrEqual(t, time.Since(start), d)
+	}
+
+	r := retry.New(func(error) bool { return true }, 5, 2, 10).WithTimerWheel(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count := 0
+			err := r.Do(func() error {
+				count = count + 1
+				return errors.New("fail")
+			})
+			assert.ErrorIs(t, err, retry.ErrExhausted)
+			assert.Equal(t, 5, count)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTimerWheelStop(t *testing.T) {
+	w := retry.NewTimerWheel(time.Millisecond, 8)
+	ch := w.After(time.Hour)
+	w.Stop()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("waiter is not released by Stop")
+	}
+	<-w.After(time.Hour)
+}
+
+func TestTimerWheelLongDelay(t *testing.T) {
+	for _, tick := range []time.Duration{time.Nanosecond, time.Millisecond, 3 * time.Millisecond} {
+		w := retry.NewTimerWheel(tick, 8)
+		for _, d := range []time.Duration{time.Duration(math.MaxInt64), time.Duration(math.MaxInt64) - 1, 24 * time.Hour} {
+			select {
+			case <-w.After(d):
+				t.Fatalf("a delay of %v fired at once with a tick of %v", d, tick)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		w.Stop()
+	}
+}
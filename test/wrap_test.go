@@ -0,0 +1,38 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bluexlab/retry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	type key struct{}
+	count := 0
+	fetch := retry.Wrap(retry.New(func(error) bool { return true }, 3, 0, 0), func(ctx context.Context) (string, error) {
+		count = count + 1
+		if count%2 == 1 {
+			return "", errors.New("fail")
+		}
+		return ctx.Value(key{}).(string), nil
+	})
+
+	ctx := context.WithValue(context.Background(), key{}, "page-1")
+	page, err := fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "page-1", page)
+	assert.Equal(t, 2, count)
+
+	// every call retries afresh.
+	page, err = fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "page-1", page)
+	assert.Equal(t, 4, count)
+
+	r := retry.WithRetryIfValue(retry.New(func(error) bool { return false }, 2, 0, 0), func(token string) bool { return token == "" })
+	_, err = retry.Wrap(r, func(ctx context.Context) (string, error) { return "", nil })(ctx)
+	assert.ErrorIs(t, err, retry.ErrUnacceptedValue)
+}
@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is the abort cause when an AdaptiveThrottle holds back an attempt.
+var ErrThrottled = errors.New("throttled by client")
+
+// AdaptiveThrottle throttles attempts on the client side while the upstream is throttling,
+// similar to the adaptive retry mode of the AWS SDK. It measures, over a sliding window, how many
+// attempts were made and how many were accepted, i.e. not rejected with a throttling error, and
+// drops attempts with the probability
+//
+//	max(0, (attempts - k * accepted) / (attempts + 1))
+//
+// so the load it sends stays around k times what the upstream accepts. Only retries are throttled
+// unless ThrottleFirstAttempts is set. It is safe for concurrent use by many Retry instances.
+type AdaptiveThrottle struct {
+	isThrottle    func(error) bool
+	k             float64
+	firstAttempts bool
+
+	mu     sync.Mutex
+	window slidingWindow
+	rand   lockedRand
+}
+
+// NewAdaptiveThrottle creates an AdaptiveThrottle measuring over window, where isThrottle tells
+// the throttling errors from others. k is usually 2; a lower k throttles more aggressively.
+func NewAdaptiveThrottle(isThrottle func(error) bool, k float64, window time.Duration) *AdaptiveThrottle {
+	return &AdaptiveThrottle{
+		isThrottle: isThrottle,
+		k:          k,
+		window:     newSlidingWindow(window),
+	}
+}
+
+// ThrottleFirstAttempts makes a throttle hold back first attempts too, not only retries.
+// It must be called before a throttle is used.
+func (a *AdaptiveThrottle) ThrottleFirstAttempts() *AdaptiveThrottle {
+	a.firstAttempts = true
+	return a
+}
+
+// Record records the outcome of an attempt.
+func (a *AdaptiveThrottle) Record(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.window.add(err != nil && a.isThrottle(err))
+}
+
+// Probability returns the probability of an attempt being held back now.
+func (a *AdaptiveThrottle) Probability() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	accepted, throttled := a.window.count()
+	attempts := float64(accepted + throttled)
+	p := (attempts - a.k*float64(accepted)) / (attempts + 1)
+	if p < 0 {
+		return 0
+	}
+	return p
+}
+
+// allow reports whether an attempt may go ahead; first tells whether it is the first attempt.
+func (a *AdaptiveThrottle) allow(first bool) bool {
+	if first && !a.firstAttempts {
+		return true
+	}
+	p := a.Probability()
+	return p == 0 || a.rand.Float64() >= p
+}
@@ -0,0 +1,74 @@
+package retry
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnacceptedValue is the error of an attempt whose value the predicate of WithRetryIfValue
+// asked to retry. Once the attempts are exhausted, ErrMaxAttemptExceeded wraps it.
+var ErrUnacceptedValue = errors.New("value requires a retry")
+
+// unacceptedValue is ErrUnacceptedValue marked retryable, so the value is retried whatever shouldRetry says.
+var unacceptedValue = MarkRetryable(ErrUnacceptedValue)
+
+// WithRetryIfValue returns a copy of r retrying the calls of DoValue, Wrap, Retry2 and its variants returning R
+// when retryIf reports true for the value returned along with a nil error, e.g. a response with
+// an error status or an empty page token. Such an attempt fails with ErrUnacceptedValue, which is
+// retried whatever shouldRetry says, and the value of the last attempt returns along with the error
+// once the attempts are exhausted. It is a function rather than a method, as methods cannot have
+// type parameters; the calls returning other types than R ignore retryIf.
+func WithRetryIfValue[R any](r Retry, retryIf func(R) bool) Retry {
+	r.retryIfValue = retryIf
+	return r
+}
+
+// checkValue returns the error of an attempt of r which returned v and err.
+func checkValue[R any](r Retry, v R, err error) error {
+	if err != nil || r.retryIfValue == nil {
+		return err
+	}
+	if retryIf, ok := r.retryIfValue.(func(R) bool); ok && retryIf(v) {
+		return unacceptedValue
+	}
+	return nil
+}
+
+// DoValue is like DoContext, but for a function returning a value along with its error. The value
+// of the last attempt returns, so f can be a closure over whatever parameters it needs.
+// It honors WithRetryIfValue.
+func DoValue[R any](ctx context.Context, r Retry, f func(ctx context.Context) (R, error)) (R, error) {
+	var result R
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		var e error
+		result, e = f(ctx)
+		return checkValue(r, result, e)
+	})
+	return result, err
+}
+
+// DoValues2 is like DoValue, but for a function returning two values along with its error.
+func DoValues2[R1, R2 any](ctx context.Context, r Retry, f func(ctx context.Context) (R1, R2, error)) (R1, R2, error) {
+	var result1 R1
+	var result2 R2
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		var e error
+		result1, result2, e = f(ctx)
+		return e
+	})
+	return result1, result2, err
+}
+
+// DoValues3 is like DoValue, but for a function returning three values along with its error, such as
+// a value, its metadata and a continuation token. For more values, return them in a struct from DoValue.
+func DoValues3[R1, R2, R3 any](ctx context.Context, r Retry, f func(ctx context.Context) (R1, R2, R3, error)) (R1, R2, R3, error) {
+	var result1 R1
+	var result2 R2
+	var result3 R3
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		var e error
+		result1, result2, result3, e = f(ctx)
+		return e
+	})
+	return result1, result2, result3, err
+}
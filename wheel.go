@@ -0,0 +1,145 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// TimerWheel is a timer shared by many concurrently sleeping retries. Instead of a runtime timer
+// for every delay, the sleeping retries are parked in the slots of a wheel which a single goroutine
+// advances every tick, so tens of thousands of pending delays cost a few words each.
+// Delays are rounded up to a multiple of the tick. A Scheduler parks whole operations on a wheel,
+// without even a goroutine for each.
+type TimerWheel struct {
+	tick time.Duration
+
+	mu      sync.Mutex
+	slots   [][]wheelWaiter
+	pos     int
+	stopped bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type wheelWaiter struct {
+	rounds int
+	ch     chan struct{} // closed when due, unless fn is set
+	fn     func()        // called when due, with the wheel locked
+}
+
+// fire wakes up the waiter.
+func (w wheelWaiter) fire() {
+	if w.fn != nil {
+		w.fn()
+	} else {
+		close(w.ch)
+	}
+}
+
+// NewTimerWheel creates a TimerWheel advancing every tick and holding slots slots.
+// A delay longer than tick * slots waits for more rounds of the wheel.
+// Stop must be called to release the goroutine of the wheel.
+func NewTimerWheel(tick time.Duration, slots int) *TimerWheel {
+	if tick <= 0 {
+		panic("tick must be greater than 0")
+	}
+	if slots <= 0 {
+		panic("slots must be greater than 0")
+	}
+	w := &TimerWheel{
+		tick:  tick,
+		slots: make([][]wheelWaiter, slots),
+		stop:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// After returns a channel which is closed once d elapsed.
+func (w *TimerWheel) After(d time.Duration) <-chan struct{} {
+	ch := make(chan struct{})
+	w.park(d, wheelWaiter{ch: ch})
+	return ch
+}
+
+// afterFunc calls fn once d elapsed, from the goroutine of the wheel with the wheel locked,
+// or right away if d is not positive or the wheel is stopped. fn must be quick and not use the wheel.
+func (w *TimerWheel) afterFunc(d time.Duration, fn func()) {
+	w.park(d, wheelWaiter{fn: fn})
+}
+
+func (w *TimerWheel) park(d time.Duration, waiter wheelWaiter) {
+	// rounded up without adding to d, which may be close to math.MaxInt64.
+	ticks := int(d / w.tick)
+	if d%w.tick > 0 {
+		ticks++
+	}
+	if ticks <= 0 {
+		waiter.fire()
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		waiter.fire()
+		return
+	}
+	n := len(w.slots)
+	slot := (w.pos + ticks%n) % n
+	waiter.rounds = (ticks - 1) / n
+	w.slots[slot] = append(w.slots[slot], waiter)
+}
+
+// Stop stops the wheel and wakes up every waiter immediately.
+func (w *TimerWheel) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.stopped = true
+		for i, waiters := range w.slots {
+			for _, waiter := range waiters {
+				waiter.fire()
+			}
+			w.slots[i] = nil
+		}
+	})
+}
+
+func (w *TimerWheel) run() {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.advance()
+		}
+	}
+}
+
+func (w *TimerWheel) advance() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return
+	}
+	w.pos = (w.pos + 1) % len(w.slots)
+	waiters := w.slots[w.pos]
+	kept := waiters[:0]
+	for _, waiter := range waiters {
+		if waiter.rounds == 0 {
+			waiter.fire()
+			continue
+		}
+		waiter.rounds--
+		kept = append(kept, waiter)
+	}
+	for i := len(kept); i < len(waiters); i++ {
+		waiters[i] = wheelWaiter{}
+	}
+	w.slots[w.pos] = kept
+}
@@ -0,0 +1,58 @@
+package retry
+
+import "time"
+
+// windowBuckets is the number of buckets a slidingWindow divides its span into.
+const windowBuckets = 10
+
+// slidingWindow counts successes and failures over a sliding span of time, in buckets.
+// It is not safe for concurrent use.
+type slidingWindow struct {
+	width   time.Duration
+	origin  time.Time // the monotonic reference of the buckets
+	buckets [windowBuckets]windowBucket
+}
+
+type windowBucket struct {
+	epoch     int64
+	successes int
+	failures  int
+}
+
+func newSlidingWindow(span time.Duration) slidingWindow {
+	width := span / windowBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return slidingWindow{width: width, origin: time.Now()}
+}
+
+// now returns the time elapsed since the window was created.
+func (w *slidingWindow) now() time.Duration {
+	return time.Since(w.origin)
+}
+
+func (w *slidingWindow) add(failed bool) {
+	epoch := int64(w.now() / w.width)
+	b := &w.buckets[epoch%windowBuckets]
+	if b.epoch != epoch {
+		// last used in an earlier round.
+		*b = windowBucket{epoch: epoch}
+	}
+	if failed {
+		b.failures++
+	} else {
+		b.successes++
+	}
+}
+
+func (w *slidingWindow) count() (successes int, failures int) {
+	epoch := int64(w.now() / w.width)
+	for _, b := range w.buckets {
+		if epoch-b.epoch < windowBuckets {
+			successes += b.successes
+			failures += b.failures
+		}
+	}
+	return successes, failures
+}
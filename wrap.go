@@ -0,0 +1,11 @@
+package retry
+
+import "context"
+
+// Wrap returns a version of f retried with r, so a function can be decorated once, e.g. when a
+// client is constructed, rather than at every call. The retrying f honors WithRetryIfValue.
+func Wrap[R any](r Retry, f func(ctx context.Context) (R, error)) func(ctx context.Context) (R, error) {
+	return func(ctx context.Context) (R, error) {
+		return DoValue(ctx, r, f)
+	}
+}